@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog is the content of a catalog file, listing the error codes to generate.
+//
+// Fields:
+//   - Codes ([]Code): the error codes, in the order they are generated
+type Catalog struct {
+	Codes []Code `yaml:"codes"`
+}
+
+// Code describes a single error kind of a catalog.
+//
+// Fields:
+//   - Name (string): the Go name of the code, used in the generated identifiers (e.g. "OrderNotFound")
+//   - Type (string): the error Type (e.g. "payments.order_not_found")
+//   - Parent (string): the parent Type, registered with RegisterType, if any
+//   - Message (string): the message template, with "{param}" placeholders
+//   - Description (string): a description of the code, used in doc comments and the Markdown table
+//   - Params ([]Param): the parameters of the message template, in the order of the constructor's arguments
+//   - Fields (map[string]any): default fields attached to every error of this code
+//   - Status (int): the HTTP status code of this code, if any
+//   - Retryable (*bool): whether an operation failing with this code can be retried, or nil to
+//     follow the parent's rule
+type Code struct {
+	Name        string         `yaml:"name"`
+	Type        string         `yaml:"type"`
+	Parent      string         `yaml:"parent"`
+	Message     string         `yaml:"message"`
+	Description string         `yaml:"description"`
+	Params      []Param        `yaml:"params"`
+	Fields      map[string]any `yaml:"fields"`
+	Status      int            `yaml:"status"`
+	Retryable   *bool          `yaml:"retryable"`
+}
+
+// Param describes a parameter of a message template.
+// Each parameter becomes an argument of the generated constructors and a field of the errors they create.
+//
+// Fields:
+//   - Name (string): the placeholder and field name (e.g. "order_id")
+//   - Type (string): the Go type of the argument (default: "any")
+type Param struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// placeholderRegex matches the "{param}" placeholders of message templates.
+var placeholderRegex = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// loadCatalog reads and validates a catalog file. Both YAML and JSON files are supported,
+// as JSON documents are valid YAML.
+//
+// Parameters:
+//   - path (string): the catalog file path
+//
+// Returns:
+//   - catalog (*Catalog): the validated catalog
+//   - err (error): any error reading, decoding or validating the catalog
+func loadCatalog(path string) (catalog *Catalog, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	catalog, err = parseCatalog(data)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+
+	return
+}
+
+// parseCatalog decodes and validates a catalog, applying defaults:
+// the Type defaults to the Name, parameter types default to "any", and placeholders
+// of the message without a declared parameter are added as "any" parameters.
+//
+// Parameters:
+//   - data ([]byte): the YAML or JSON document
+//
+// Returns:
+//   - catalog (*Catalog): the validated catalog
+//   - err (error): any error decoding or validating the catalog
+func parseCatalog(data []byte) (catalog *Catalog, err error) {
+	catalog = &Catalog{}
+
+	if err = yaml.Unmarshal(data, catalog); err != nil {
+		return
+	}
+
+	names := map[string]bool{}
+	types := map[string]bool{}
+
+	for i := range catalog.Codes {
+		code := &catalog.Codes[i]
+
+		if err = code.normalize(); err != nil {
+			err = fmt.Errorf("code %d: %w", i+1, err)
+
+			return
+		}
+
+		if names[code.Name] {
+			err = fmt.Errorf("code %q: duplicate name", code.Name)
+
+			return
+		}
+
+		if types[code.Type] {
+			err = fmt.Errorf("code %q: duplicate type %q", code.Name, code.Type)
+
+			return
+		}
+
+		names[code.Name] = true
+		types[code.Type] = true
+	}
+
+	return
+}
+
+// normalize validates a code and applies its defaults. Parameters must map to
+// distinct Go identifiers, as they become the arguments of the generated constructors.
+//
+// Returns:
+//   - err (error): the validation error, if any
+func (c *Code) normalize() (err error) {
+	if c.Name == "" {
+		err = errors.New("missing name")
+
+		return
+	}
+
+	runes := []rune(c.Name)
+
+	runes[0] = unicode.ToUpper(runes[0])
+
+	c.Name = string(runes)
+
+	if !token.IsIdentifier(c.Name) {
+		err = fmt.Errorf("name %q is not a valid Go identifier", c.Name)
+
+		return
+	}
+
+	if c.Type == "" {
+		c.Type = c.Name
+	}
+
+	if c.Message == "" {
+		err = fmt.Errorf("code %q: missing message", c.Name)
+
+		return
+	}
+
+	declared := map[string]bool{}
+
+	for i := range c.Params {
+		param := &c.Params[i]
+
+		if param.Name == "" {
+			err = fmt.Errorf("code %q: parameter %d: missing name", c.Name, i+1)
+
+			return
+		}
+
+		if declared[param.Name] {
+			err = fmt.Errorf("code %q: duplicate parameter %q", c.Name, param.Name)
+
+			return
+		}
+
+		if param.Type == "" {
+			param.Type = "any"
+		}
+
+		declared[param.Name] = true
+	}
+
+	for _, match := range placeholderRegex.FindAllStringSubmatch(c.Message, -1) {
+		if !declared[match[1]] {
+			c.Params = append(c.Params, Param{Name: match[1], Type: "any"})
+
+			declared[match[1]] = true
+		}
+	}
+
+	identifiers := map[string]string{}
+
+	for _, param := range c.Params {
+		ident := identifier(param.Name)
+
+		if other, ok := identifiers[ident]; ok {
+			err = fmt.Errorf("code %q: parameters %q and %q both map to the Go identifier %q", c.Name, other, param.Name, ident)
+
+			return
+		}
+
+		identifiers[ident] = param.Name
+	}
+
+	return
+}
+
+// identifier converts a parameter name (e.g. "order_id") into a Go identifier for
+// the generated constructor argument (e.g. "orderID").
+//
+// Parameters:
+//   - name (string): the parameter name
+//
+// Returns:
+//   - ident (string): the Go identifier
+func identifier(name string) (ident string) {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+
+	for i, word := range words {
+		switch {
+		case initialisms[strings.ToLower(word)] && i > 0:
+			b.WriteString(strings.ToUpper(word))
+		case i == 0:
+			b.WriteString(strings.ToLower(word))
+		default:
+			b.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+		}
+	}
+
+	ident = b.String()
+
+	if !token.IsIdentifier(ident) || reserved[ident] {
+		ident = "p" + strings.ToUpper(ident[:min(1, len(ident))]) + ident[min(1, len(ident)):]
+	}
+
+	return
+}
+
+// reserved lists the identifiers used by the generated constructors, which parameters must not shadow.
+var reserved = map[string]bool{
+	"cause":      true,
+	"fmt":        true,
+	"hqgoerrors": true,
+	"ofs":        true,
+}
+
+// initialisms lists the words written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"api":  true,
+	"http": true,
+	"id":   true,
+	"ip":   true,
+	"json": true,
+	"sql":  true,
+	"uri":  true,
+	"url":  true,
+	"uuid": true,
+}
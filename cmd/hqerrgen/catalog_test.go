@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatalog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("yaml", func(t *testing.T) {
+		t.Parallel()
+
+		catalog, err := parseCatalog([]byte(`
+codes:
+  - name: orderNotFound
+    message: "order {order_id} not found in {store}"
+    params:
+      - name: order_id
+        type: string
+    status: 404
+`))
+
+		require.NoError(t, err)
+		require.Len(t, catalog.Codes, 1)
+
+		code := catalog.Codes[0]
+
+		assert.Equal(t, "OrderNotFound", code.Name)
+		assert.Equal(t, "OrderNotFound", code.Type)
+		assert.Equal(t, []Param{{Name: "order_id", Type: "string"}, {Name: "store", Type: "any"}}, code.Params)
+		assert.Equal(t, 404, code.Status)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		catalog, err := parseCatalog([]byte(`{"codes": [{"name": "Timeout", "type": "db.timeout", "parent": "db", "message": "timed out", "retryable": true, "fields": {"component": "db"}}]}`))
+
+		require.NoError(t, err)
+		require.Len(t, catalog.Codes, 1)
+
+		code := catalog.Codes[0]
+
+		assert.Equal(t, "db.timeout", code.Type)
+		assert.Equal(t, "db", code.Parent)
+		require.NotNil(t, code.Retryable)
+		assert.True(t, *code.Retryable)
+		assert.Equal(t, map[string]any{"component": "db"}, code.Fields)
+	})
+
+	tests := []struct {
+		name    string
+		catalog string
+	}{
+		{name: "malformed", catalog: `codes: [`},
+		{name: "missing name", catalog: `codes: [{message: "m"}]`},
+		{name: "invalid name", catalog: `codes: [{name: "order-not-found", message: "m"}]`},
+		{name: "missing message", catalog: `codes: [{name: "A"}]`},
+		{name: "duplicate name", catalog: `codes: [{name: "A", type: "a", message: "m"}, {name: "A", type: "b", message: "m"}]`},
+		{name: "duplicate type", catalog: `codes: [{name: "A", type: "a", message: "m"}, {name: "B", type: "a", message: "m"}]`},
+		{name: "duplicate parameter", catalog: `codes: [{name: "A", message: "m", params: [{name: "p"}, {name: "p"}]}]`},
+		{name: "conflicting parameter identifiers", catalog: `codes: [{name: "A", message: "order {order-id}", params: [{name: "order_id"}]}]`},
+		{name: "conflicting placeholder identifiers", catalog: `codes: [{name: "A", message: "{order_id} {order_ID}"}]`},
+		{name: "unnamed parameter", catalog: `codes: [{name: "A", message: "m", params: [{type: "int"}]}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseCatalog([]byte(tt.catalog))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "order_id", expected: "orderID"},
+		{name: "provider", expected: "provider"},
+		{name: "Request-URL", expected: "requestURL"},
+		{name: "user.name", expected: "userName"},
+		{name: "id", expected: "id"},
+		{name: "type", expected: "pType"},
+		{name: "ofs", expected: "pOfs"},
+		{name: "2fa_code", expected: "p2faCode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, identifier(tt.name))
+		})
+	}
+}
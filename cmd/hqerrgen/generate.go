@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// generatedHeader marks generated files, as recognized by Go tools.
+const generatedHeader = "// Code generated by hqerrgen. DO NOT EDIT."
+
+// goTemplate is the template of the generated Go file.
+var goTemplate = template.Must(template.New("go").Parse(generatedHeader + `
+
+package {{ .Package }}
+
+import (
+{{- if .UsesFmt }}
+	"fmt"
+{{ end }}
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+var (
+{{- range .Codes }}
+	// {{ .Sentinel }} is the type of {{ .Name }} errors.{{ with .Description }}
+	// {{ . }}{{ end }}
+	// It can be used with WithType, IsType and, through TypeMatcher, with Is.
+	{{ .Sentinel }} = {{ if .Parent }}hqgoerrors.RegisterType({{ printf "%q" .Type }}, {{ .ParentExpr }}){{ else }}hqgoerrors.Type({{ printf "%q" .Type }}){{ end }}
+{{- end }}
+)
+
+// Statuses maps the types of this catalog to their HTTP status codes.
+var Statuses = map[hqgoerrors.Type]int{
+{{- range .Codes }}{{ if .Status }}
+	{{ .Sentinel }}: {{ .Status }},
+{{- end }}{{ end }}
+}
+
+// RetryableTypes maps the types of this catalog with an explicit retry rule to whether
+// their operations can be retried.
+var RetryableTypes = map[hqgoerrors.Type]bool{
+{{- range .Codes }}{{ if .RetryableExpr }}
+	{{ .Sentinel }}: {{ .RetryableExpr }},
+{{- end }}{{ end }}
+}
+
+func init() {
+	for errType, retryable := range RetryableTypes {
+		hqgoerrors.RegisterRetryableType(errType, retryable)
+	}
+}
+{{ range .Codes }}
+// New{{ .Name }} creates a {{ .Name }} error.{{ with .Description }}
+// {{ . }}{{ end }}
+// The stack trace starts at the caller.
+func New{{ .Name }}({{ .Args }}ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New({{ .MessageExpr }}, append({{ .Options }}, ofs...)...)
+}
+
+// Wrap{{ .Name }} wraps cause with a {{ .Name }} error.{{ with .Description }}
+// {{ . }}{{ end }}
+// The stack trace starts at the caller.
+func Wrap{{ .Name }}(cause error, {{ .Args }}ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.Wrap(cause, {{ .MessageExpr }}, append({{ .Options }}, ofs...)...)
+}
+{{ end }}`))
+
+// goFile holds the data of the generated Go file.
+//
+// Fields:
+//   - Package (string): the package name
+//   - UsesFmt (bool): whether a message template has parameters, so fmt is imported
+//   - Codes ([]goCode): the codes to generate
+type goFile struct {
+	Package string
+	UsesFmt bool
+	Codes   []goCode
+}
+
+// goCode holds the data generated for a single code.
+//
+// Fields:
+//   - Code (Code): the catalog code
+//   - Sentinel (string): the name of the Type variable
+//   - ParentExpr (string): the expression of the parent Type, the parent's variable if it is in the catalog
+//   - Args (string): the constructor arguments of the message parameters, each followed by ", "
+//   - MessageExpr (string): the expression building the message
+//   - Options (string): the expression of the default options
+//   - RetryableExpr (string): "true" or "false" if the code has a retry rule, or empty
+type goCode struct {
+	Code
+	Sentinel      string
+	ParentExpr    string
+	Args          string
+	MessageExpr   string
+	Options       string
+	RetryableExpr string
+}
+
+// generateGo generates the Go source of a catalog, formatted with gofmt.
+//
+// Parameters:
+//   - catalog (*Catalog): the validated catalog
+//   - pkg (string): the package name of the generated file
+//
+// Returns:
+//   - source ([]byte): the Go source
+//   - err (error): any error generating or formatting the source
+func generateGo(catalog *Catalog, pkg string) (source []byte, err error) {
+	file := goFile{
+		Package: pkg,
+	}
+
+	sentinels := map[string]string{}
+
+	for _, code := range catalog.Codes {
+		sentinels[code.Type] = "Err" + code.Name
+	}
+
+	for _, code := range catalog.Codes {
+		generated := goCode{
+			Code:       code,
+			Sentinel:   sentinels[code.Type],
+			ParentExpr: sentinels[code.Parent],
+		}
+
+		if generated.ParentExpr == "" {
+			generated.ParentExpr = strconv.Quote(code.Parent)
+		}
+
+		var args []string
+
+		for _, param := range code.Params {
+			args = append(args, identifier(param.Name)+" "+param.Type+", ")
+		}
+
+		generated.Args = strings.Join(args, "")
+		generated.MessageExpr = messageExpr(&code)
+		generated.Options = optionsExpr(&code, generated.Sentinel)
+
+		if code.Retryable != nil {
+			generated.RetryableExpr = strconv.FormatBool(*code.Retryable)
+		}
+
+		if len(code.Params) > 0 {
+			file.UsesFmt = true
+		}
+
+		file.Codes = append(file.Codes, generated)
+	}
+
+	var buf bytes.Buffer
+
+	if err = goTemplate.Execute(&buf, file); err != nil {
+		return
+	}
+
+	source, err = format.Source(buf.Bytes())
+	if err != nil {
+		err = fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	return
+}
+
+// messageExpr returns the Go expression building a code's message from its template:
+// a string literal, or a fmt.Sprintf call if the template has placeholders.
+//
+// Parameters:
+//   - code (*Code): the code
+//
+// Returns:
+//   - expr (string): the Go expression
+func messageExpr(code *Code) (expr string) {
+	matches := placeholderRegex.FindAllStringSubmatchIndex(code.Message, -1)
+
+	if len(matches) == 0 {
+		expr = strconv.Quote(code.Message)
+
+		return
+	}
+
+	var (
+		format strings.Builder
+		args   []string
+		last   int
+	)
+
+	for _, match := range matches {
+		format.WriteString(strings.ReplaceAll(code.Message[last:match[0]], "%", "%%"))
+		format.WriteString("%v")
+
+		args = append(args, identifier(code.Message[match[2]:match[3]]))
+
+		last = match[1]
+	}
+
+	format.WriteString(strings.ReplaceAll(code.Message[last:], "%", "%%"))
+
+	expr = fmt.Sprintf("fmt.Sprintf(%s, %s)", strconv.Quote(format.String()), strings.Join(args, ", "))
+
+	return
+}
+
+// optionsExpr returns the Go expression of the default options of a code: its type,
+// its parameters and default fields as fields, and a caller skip so stacks start at the caller.
+//
+// Parameters:
+//   - code (*Code): the code
+//   - sentinel (string): the name of the code's Type variable
+//
+// Returns:
+//   - expr (string): the Go expression, a []hqgoerrors.OptionFunc literal
+func optionsExpr(code *Code, sentinel string) (expr string) {
+	options := []string{
+		"hqgoerrors.WithType(" + sentinel + ")",
+	}
+
+	keys := make([]string, 0, len(code.Fields))
+
+	for key := range code.Fields {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		options = append(options, fmt.Sprintf("hqgoerrors.WithField(%s, %#v)", strconv.Quote(key), code.Fields[key]))
+	}
+
+	for _, param := range code.Params {
+		options = append(options, fmt.Sprintf("hqgoerrors.WithField(%s, %s)", strconv.Quote(param.Name), identifier(param.Name)))
+	}
+
+	options = append(options, "hqgoerrors.WithCallerSkip(1)")
+
+	expr = "[]hqgoerrors.OptionFunc{\n" + strings.Join(options, ",\n") + ",\n}"
+
+	return
+}
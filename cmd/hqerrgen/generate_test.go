@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCatalog = `
+codes:
+  - name: DB
+    type: db
+    message: database error
+    status: 500
+  - name: QueryTimeout
+    type: db.timeout
+    parent: db
+    message: "query {query} timed out after 100% of {timeout_ms}ms"
+    description: The query | took too long.
+    params:
+      - name: timeout_ms
+        type: int
+    fields:
+      component: db
+    status: 504
+    retryable: true
+  - name: QuerySlow
+    type: db.timeout.slow
+    parent: db.timeout
+    message: slow query
+  - name: QueryInvalid
+    type: db.timeout.invalid
+    parent: db.timeout
+    message: invalid query
+    retryable: false
+`
+
+func TestGenerateGo(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := parseCatalog([]byte(testCatalog))
+
+	require.NoError(t, err)
+
+	source, err := generateGo(catalog, "codes")
+
+	require.NoError(t, err)
+
+	generated := string(source)
+
+	assert.Contains(t, generated, generatedHeader+"\n\npackage codes\n")
+	assert.Contains(t, generated, `ErrDB = hqgoerrors.Type("db")`)
+	assert.Contains(t, generated, `ErrQueryTimeout = hqgoerrors.RegisterType("db.timeout", ErrDB)`)
+	assert.Contains(t, generated, "ErrQueryTimeout: 504,")
+	assert.Contains(t, generated, "ErrQueryTimeout: true,")
+	assert.Contains(t, generated, "ErrQueryInvalid: false,")
+	assert.NotContains(t, generated, "ErrDB: false,")
+	assert.NotContains(t, generated, "ErrDB: true,")
+	assert.Contains(t, generated, "hqgoerrors.RegisterRetryableType(errType, retryable)")
+	assert.Contains(t, generated, "func NewQueryTimeout(timeoutMs int, query any, ofs ...hqgoerrors.OptionFunc) error {")
+	assert.Contains(t, generated, "func WrapQueryTimeout(cause error, timeoutMs int, query any, ofs ...hqgoerrors.OptionFunc) error {")
+	assert.Contains(t, generated, `fmt.Sprintf("query %v timed out after 100%% of %vms", query, timeoutMs)`)
+	assert.Contains(t, generated, `hqgoerrors.WithField("component", "db")`)
+	assert.Contains(t, generated, `hqgoerrors.WithField("timeout_ms", timeoutMs)`)
+	assert.Contains(t, generated, "hqgoerrors.WithCallerSkip(1)")
+	assert.Contains(t, generated, `func NewDB(ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New("database error"`)
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := parseCatalog([]byte(testCatalog))
+
+	require.NoError(t, err)
+
+	doc := string(generateMarkdown(catalog))
+
+	assert.Contains(t, doc, "| `DB` | `db` |  | 500 Internal Server Error |  | `database error` |  |\n")
+	assert.Contains(t, doc, "| `QuerySlow` | `db.timeout.slow` | `db.timeout` |  | inherited | `slow query` |  |\n")
+	assert.Contains(t, doc, "| `QueryInvalid` | `db.timeout.invalid` | `db.timeout` |  | no | `invalid query` |  |\n")
+	assert.Contains(t, doc, "| `QueryTimeout` | `db.timeout` | `db` | 504 Gateway Timeout | yes | `query {query} timed out after 100% of {timeout_ms}ms` | The query \\| took too long. |\n")
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	in := filepath.Join(dir, "codes.yaml")
+	out := filepath.Join(dir, "codes_gen.go")
+	doc := filepath.Join(dir, "CODES.md")
+
+	require.NoError(t, os.WriteFile(in, []byte(testCatalog), 0o600))
+	require.NoError(t, run([]string{"-in", in, "-out", out, "-doc", doc, "-pkg", "codes"}))
+
+	assert.FileExists(t, out)
+	assert.FileExists(t, doc)
+
+	assert.Error(t, run([]string{"-out", out, "-pkg", "codes"}))
+	assert.Error(t, run([]string{"-in", filepath.Join(dir, "missing.yaml"), "-pkg", "codes"}))
+}
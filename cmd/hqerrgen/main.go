@@ -0,0 +1,104 @@
+// Command hqerrgen generates error constructors and documentation from a catalog of error codes.
+//
+// A catalog is a YAML or JSON file listing codes:
+//
+//	codes:
+//	  - name: OrderNotFound
+//	    type: payments.order_not_found
+//	    parent: payments
+//	    message: "order {order_id} not found"
+//	    description: The order does not exist.
+//	    params:
+//	      - name: order_id
+//	        type: string
+//	    fields:
+//	      component: orders
+//	    status: 404
+//	    retryable: false
+//
+// For each code, hqerrgen emits an ErrOrderNotFound Type (registered under its parent, if any)
+// usable with WithType, IsType and Is, and NewOrderNotFound / WrapOrderNotFound constructors
+// taking the message parameters, which are also attached as fields. The HTTP statuses and
+// retry rules of the catalog are emitted as the Statuses and RetryableTypes maps, and the
+// retry rules are registered with RegisterRetryableType, so IsRetryable and Retry honor them.
+// A code with "retryable: false" opts out of its parent's rule; a code without "retryable"
+// follows it.
+// A Markdown table of the codes can be emitted alongside, for runbooks.
+//
+// Usage:
+//
+//	//go:generate go run github.com/hueristiq/hq-go-errors/cmd/hqerrgen -in codes.yaml -out codes_gen.go -doc CODES.md
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "hqerrgen:", err)
+
+		os.Exit(1)
+	}
+}
+
+// run parses the command line arguments and generates the requested files.
+//
+// Parameters:
+//   - args ([]string): the command line arguments, without the program name
+//
+// Returns:
+//   - err (error): any error parsing the arguments, loading the catalog or writing the files
+func run(args []string) (err error) {
+	flags := flag.NewFlagSet("hqerrgen", flag.ContinueOnError)
+
+	in := flags.String("in", "", "catalog file (YAML or JSON)")
+	out := flags.String("out", "", "generated Go file (default: stdout)")
+	doc := flags.String("doc", "", "generated Markdown file (default: none)")
+	pkg := flags.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated Go file (default: $GOPACKAGE)")
+
+	if err = flags.Parse(args); err != nil {
+		return
+	}
+
+	if *in == "" {
+		err = errors.New("missing -in catalog file")
+
+		return
+	}
+
+	if *pkg == "" {
+		err = errors.New("missing -pkg package name")
+
+		return
+	}
+
+	catalog, err := loadCatalog(*in)
+	if err != nil {
+		return
+	}
+
+	source, err := generateGo(catalog, *pkg)
+	if err != nil {
+		return
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(source)
+	} else {
+		err = os.WriteFile(*out, source, 0o644)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if *doc != "" {
+		err = os.WriteFile(*doc, generateMarkdown(catalog), 0o644)
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// generateMarkdown generates the Markdown table documenting the codes of a catalog.
+// Codes without a retryable rule are shown as inheriting it from their parent type.
+//
+// Parameters:
+//   - catalog (*Catalog): the validated catalog
+//
+// Returns:
+//   - doc ([]byte): the Markdown document
+func generateMarkdown(catalog *Catalog) (doc []byte) {
+	var b strings.Builder
+
+	b.WriteString("<!-- " + strings.TrimPrefix(generatedHeader, "// ") + " -->\n\n")
+	b.WriteString("| Code | Type | Parent | HTTP Status | Retryable | Message | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, code := range catalog.Codes {
+		status := ""
+
+		if code.Status != 0 {
+			status = fmt.Sprintf("%d %s", code.Status, http.StatusText(code.Status))
+		}
+
+		// without a rule of its own, a code is retryable if its parent is
+		retryable := ""
+
+		switch {
+		case code.Retryable != nil && *code.Retryable:
+			retryable = "yes"
+		case code.Retryable != nil:
+			retryable = "no"
+		case code.Parent != "":
+			retryable = "inherited"
+		}
+
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s | %s | %s | %s |\n",
+			code.Name,
+			code.Type,
+			markdownCode(code.Parent),
+			status,
+			retryable,
+			markdownCode(code.Message),
+			markdownEscape(code.Description),
+		)
+	}
+
+	doc = []byte(b.String())
+
+	return
+}
+
+// markdownCode formats s as inline code, or returns an empty string if s is empty.
+//
+// Parameters:
+//   - s (string): the text
+//
+// Returns:
+//   - cell (string): the table cell content
+func markdownCode(s string) (cell string) {
+	if s == "" {
+		return
+	}
+
+	cell = "`" + markdownEscape(s) + "`"
+
+	return
+}
+
+// markdownEscape escapes the characters that would break a table cell.
+//
+// Parameters:
+//   - s (string): the text
+//
+// Returns:
+//   - escaped (string): the escaped text
+func markdownEscape(s string) (escaped string) {
+	escaped = strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+
+	return
+}
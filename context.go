@@ -0,0 +1,291 @@
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Well-known types for errors caused by a done context. TypeCanceled and
+// TypeDeadlineExceeded are registered as children of TypeContext, so
+// IsType(err, TypeContext) matches both.
+var (
+	TypeContext          = Type("context")
+	TypeCanceled         = RegisterType("context.canceled", TypeContext)
+	TypeDeadlineExceeded = RegisterType("context.deadline_exceeded", TypeContext)
+)
+
+// ContextExtractor extracts fields from a context.Context.
+// It is called for every error created with NewCtx, WrapCtx or WithContext,
+// so it should be cheap and must be safe for concurrent use.
+type ContextExtractor func(ctx context.Context) (fields map[string]any)
+
+// contextExtractors holds the registered extractors.
+// Registration typically happens during package initialization, while extraction
+// happens on every error creation, so it is guarded by a read-write mutex.
+//
+// Fields:
+//   - mu (sync.RWMutex): guards extractors
+//   - extractors ([]ContextExtractor): the registered extractors, in registration order
+var contextExtractors = struct {
+	mu         sync.RWMutex
+	extractors []ContextExtractor
+}{}
+
+// RegisterContextExtractor registers an extractor whose fields are added to errors
+// created with NewCtx, WrapCtx or WithContext. Extractors run in registration order,
+// so a later extractor overrides fields set by an earlier one.
+//
+// Parameters:
+//   - extractor (ContextExtractor): the extractor to register
+func RegisterContextExtractor(extractor ContextExtractor) {
+	if extractor == nil {
+		return
+	}
+
+	contextExtractors.mu.Lock()
+	defer contextExtractors.mu.Unlock()
+
+	contextExtractors.extractors = append(contextExtractors.extractors, extractor)
+}
+
+// RegisterContextKey registers a context key whose value is added to errors created with
+// NewCtx, WrapCtx or WithContext as the field named field. Contexts without a value for
+// key add nothing.
+//
+// Usage:
+//
+//	type requestIDKey struct{}
+//
+//	func init() {
+//		errors.RegisterContextKey(requestIDKey{}, "request_id")
+//	}
+//
+// Parameters:
+//   - key (any): the context key, as passed to context.WithValue
+//   - field (string): the name of the field to set
+func RegisterContextKey(key any, field string) {
+	RegisterContextExtractor(func(ctx context.Context) (fields map[string]any) {
+		if v := ctx.Value(key); v != nil {
+			fields = map[string]any{field: v}
+		}
+
+		return
+	})
+}
+
+// WithContext creates an OptionFunc that adds the fields extracted from ctx by the
+// registered extractors (see RegisterContextExtractor and RegisterContextKey).
+// Options given after it override the extracted fields.
+//
+// Parameters:
+//   - ctx (context.Context): the context to extract fields from
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithContext(ctx context.Context) (f OptionFunc) {
+	return func(err Error) {
+		if ctx == nil {
+			return
+		}
+
+		contextExtractors.mu.RLock()
+		defer contextExtractors.mu.RUnlock()
+
+		for _, extractor := range contextExtractors.extractors {
+			for k, v := range extractor(ctx) {
+				err.SetField(k, v)
+			}
+		}
+	}
+}
+
+// NewCtx creates a new root error like New, with the fields extracted from ctx
+// by the registered extractors (see WithContext).
+//
+// Parameters:
+//   - ctx (context.Context): the context to extract fields from
+//   - msg (string): the primary error message
+//   - ofs (...OptionFunc): configuration options, applied after the context fields
+//
+// Returns:
+//   - err (error): the newly created error (implements Error interface)
+func NewCtx(ctx context.Context, msg string, ofs ...OptionFunc) (err error) {
+	e := &root{
+		message: msg,
+	}
+
+	WithContext(ctx)(e)
+
+	for _, f := range ofs {
+		f(e)
+	}
+
+	e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (NewCtx), callers, and runtime.Callers
+
+	notify(EventNew, e)
+
+	err = e
+
+	return
+}
+
+// WrapCtx wraps an error like Wrap, with the fields extracted from ctx by the
+// registered extractors (see WithContext). If the new error has no type and cause is
+// caused by a done context, it is typed TypeCanceled or TypeDeadlineExceeded
+// (see ContextType).
+//
+// Parameters:
+//   - ctx (context.Context): the context to extract fields from
+//   - cause (error): the error to wrap
+//   - msg (string): additional context message
+//   - ofs (...OptionFunc): configuration options, applied after the context fields
+//
+// Returns:
+//   - err (error): the new wrapping error, or nil if cause is nil
+func WrapCtx(ctx context.Context, cause error, msg string, ofs ...OptionFunc) (err error) {
+	options := make([]OptionFunc, 0, len(ofs)+2)
+
+	options = append(options, WithContext(ctx))
+	options = append(options, ofs...)
+	options = append(options, withContextType(cause))
+
+	w := wrap(cause, msg, options)
+	if w == nil {
+		return
+	}
+
+	err = w
+
+	return
+}
+
+// ContextType classifies an error caused by a done context. It recognises
+// context.Canceled and context.DeadlineExceeded anywhere in err's tree, as well
+// as errors already typed TypeCanceled or TypeDeadlineExceeded.
+// A deadline takes precedence when both are found.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - errType (Type): TypeDeadlineExceeded, TypeCanceled, or empty if err was not caused by a done context
+func ContextType(err error) (errType Type) {
+	switch {
+	case err == nil:
+	case Is(err, context.DeadlineExceeded) || IsType(err, TypeDeadlineExceeded):
+		errType = TypeDeadlineExceeded
+	case Is(err, context.Canceled) || IsType(err, TypeCanceled):
+		errType = TypeCanceled
+	}
+
+	return
+}
+
+// IsCanceled reports whether err was caused by a canceled context (see ContextType).
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - canceled (bool): true if err was caused by a canceled context
+func IsCanceled(err error) (canceled bool) {
+	canceled = ContextType(err) == TypeCanceled
+
+	return
+}
+
+// IsDeadlineExceeded reports whether err was caused by a context deadline (see ContextType).
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - exceeded (bool): true if err was caused by a context deadline
+func IsDeadlineExceeded(err error) (exceeded bool) {
+	exceeded = ContextType(err) == TypeDeadlineExceeded
+
+	return
+}
+
+// WithCancelCause is like context.WithCancelCause, but the returned cancel function
+// records where the context was canceled. Its cause, as returned by context.Cause,
+// is always a package error with a stack trace at the cancel call site:
+//
+//   - A nil cause becomes an error of type TypeCanceled wrapping context.Canceled.
+//   - A cause that is not a package error is wrapped in one, typed as by ContextType.
+//   - A package error is used as is.
+//
+// Parameters:
+//   - parent (context.Context): the parent context
+//
+// Returns:
+//   - ctx (context.Context): the derived context
+//   - cancel (context.CancelCauseFunc): cancels ctx with the given cause
+func WithCancelCause(parent context.Context) (ctx context.Context, cancel context.CancelCauseFunc) {
+	ctx, cancelCause := context.WithCancelCause(parent)
+
+	cancel = func(cause error) {
+		if _, ok := cause.(Error); !ok {
+			if cause == nil {
+				cause = context.Canceled
+			}
+
+			cause = wrap(cause, "canceled", []OptionFunc{withContextType(cause)})
+		}
+
+		cancelCause(cause)
+	}
+
+	return
+}
+
+// FromContext returns why ctx is done as a package error, or nil if ctx is not done.
+// It returns context.Cause(ctx) as is if it is a package error, e.g. one set through
+// WithCancelCause; otherwise it wraps it, typed as by ContextType, with a stack trace
+// at the caller.
+//
+// Parameters:
+//   - ctx (context.Context): the context to inspect
+//
+// Returns:
+//   - err (error): the reason ctx is done, or nil if it is not
+func FromContext(ctx context.Context) (err error) {
+	cause := context.Cause(ctx)
+	if cause == nil {
+		return
+	}
+
+	if _, ok := cause.(Error); ok {
+		err = cause
+
+		return
+	}
+
+	w := wrap(cause, "context done", []OptionFunc{withContextType(cause)})
+	if w == nil {
+		return
+	}
+
+	err = w
+
+	return
+}
+
+// withContextType creates an OptionFunc that types an untyped error as ContextType(cause).
+//
+// Parameters:
+//   - cause (error): the error being wrapped
+//
+// Returns:
+//   - f (OptionFunc): configuration function for wrap
+func withContextType(cause error) (f OptionFunc) {
+	return func(err Error) {
+		if err.Type() != "" {
+			return
+		}
+
+		if errType := ContextType(cause); errType != "" {
+			err.SetType(errType)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contextTestKey struct{}
+
+type contextTestTenantKey struct{}
+
+func init() {
+	RegisterContextKey(contextTestKey{}, "request_id")
+	RegisterContextExtractor(func(ctx context.Context) (fields map[string]any) {
+		if tenant, ok := ctx.Value(contextTestTenantKey{}).(string); ok {
+			fields = map[string]any{"tenant": tenant, "request_id": "from-tenant-extractor"}
+		}
+
+		return
+	})
+}
+
+func TestNewCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("registered keys", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.WithValue(t.Context(), contextTestKey{}, "req-1")
+
+		err := NewCtx(ctx, "failed", WithType("TYPE"))
+
+		require.Error(t, err)
+		assert.Equal(t, "failed", err.Error())
+		assert.Equal(t, Type("TYPE"), err.(*root).Type())
+		assert.Equal(t, map[string]any{"request_id": "req-1"}, err.(*root).Fields())
+		assert.Contains(t, Unpack(err).ErrRoot.Stack[0].Name, "TestNewCtx")
+	})
+
+	t.Run("extractor order and option override", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.WithValue(t.Context(), contextTestKey{}, "req-1")
+		ctx = context.WithValue(ctx, contextTestTenantKey{}, "acme")
+
+		err := NewCtx(ctx, "failed")
+
+		assert.Equal(t, map[string]any{"request_id": "from-tenant-extractor", "tenant": "acme"}, err.(*root).Fields())
+
+		err = NewCtx(ctx, "failed", WithField("tenant", "override"))
+
+		assert.Equal(t, "override", err.(*root).Fields()["tenant"])
+	})
+
+	t.Run("empty context", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewCtx(t.Context(), "failed")
+
+		assert.Empty(t, err.(*root).Fields())
+	})
+}
+
+func TestWrapCtx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.WithValue(t.Context(), contextTestKey{}, "req-1")
+
+		base := New("base")
+
+		err := WrapCtx(ctx, base, "wrapper")
+
+		require.Error(t, err)
+		assert.Equal(t, "wrapper: base", err.Error())
+		assert.Equal(t, map[string]any{"request_id": "req-1"}, err.(*wrapped).Fields())
+		assert.Contains(t, Unpack(err).ErrChain[0].Stack[0].Name, "TestWrapCtx")
+	})
+
+	t.Run("nil cause", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, WrapCtx(t.Context(), nil, "wrapper"))
+	})
+
+	t.Run("context cause", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		cancel()
+
+		err := WrapCtx(ctx, ctx.Err(), "waiting")
+
+		assert.Equal(t, TypeCanceled, err.(*root).Type())
+		assert.ErrorIs(t, err, context.Canceled)
+
+		err = WrapCtx(ctx, ctx.Err(), "waiting", WithType("TYPE"))
+
+		assert.Equal(t, Type("TYPE"), err.(*root).Type())
+	})
+}
+
+func TestContextType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected Type
+	}{
+		{name: "nil", err: nil, expected: ""},
+		{name: "unrelated", err: New("failed"), expected: ""},
+		{name: "canceled", err: context.Canceled, expected: TypeCanceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: TypeDeadlineExceeded},
+		{name: "wrapped", err: Wrap(fmt.Errorf("query: %w", context.DeadlineExceeded), "loading"), expected: TypeDeadlineExceeded},
+		{name: "joined", err: Join(New("failed"), context.Canceled), expected: TypeCanceled},
+		{name: "typed", err: New("remote canceled", WithType(TypeCanceled)), expected: TypeCanceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, ContextType(tt.err))
+			assert.Equal(t, tt.expected == TypeCanceled, IsCanceled(tt.err))
+			assert.Equal(t, tt.expected == TypeDeadlineExceeded, IsDeadlineExceeded(tt.err))
+		})
+	}
+
+	assert.True(t, TypeCanceled.IsA(TypeContext))
+	assert.True(t, TypeDeadlineExceeded.IsA(TypeContext))
+}
+
+func TestWithCancelCause(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil cause", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := WithCancelCause(t.Context())
+
+		cancel(nil)
+
+		cause := context.Cause(ctx)
+
+		require.Error(t, cause)
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.ErrorIs(t, cause, context.Canceled)
+		assert.True(t, IsType(cause, TypeCanceled))
+		assert.Contains(t, Unpack(cause).ErrRoot.Stack[0].Name, "TestWithCancelCause")
+	})
+
+	t.Run("package error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := WithCancelCause(t.Context())
+
+		expected := New("shutting down")
+
+		cancel(expected)
+
+		assert.Equal(t, expected, context.Cause(ctx))
+		assert.Equal(t, expected, FromContext(ctx))
+	})
+
+	t.Run("external error", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := WithCancelCause(t.Context())
+
+		external := errors.New("signal received")
+
+		cancel(external)
+
+		cause := context.Cause(ctx)
+
+		assert.ErrorIs(t, cause, external)
+		assert.IsType(t, &root{}, cause)
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not done", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, FromContext(t.Context()))
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(t.Context(), time.Nanosecond)
+
+		defer cancel()
+
+		<-ctx.Done()
+
+		err := FromContext(ctx)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, TypeDeadlineExceeded, err.(*root).Type())
+		assert.Contains(t, Unpack(err).ErrRoot.Stack[0].Name, "TestFromContext")
+	})
+}
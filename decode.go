@@ -0,0 +1,326 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// externalError represents a non-package error decoded from JSON.
+// Only its message, original Go type name and, for wrappers of package errors, its cause
+// survive serialization.
+//
+// Fields:
+//   - message (string): the error message of the original error, or the wrapper's own message if cause is set
+//   - goType (string): the Go type name of the original error (e.g. "*errors.errorString")
+//   - cause (error): the decoded error the original error wrapped, if any
+type externalError struct {
+	message string
+	goType  string
+	cause   error
+}
+
+// Error implements the error interface, returning the original error message.
+//
+// Returns:
+//   - msg (string): the error message
+func (e *externalError) Error() (msg string) {
+	msg = e.message
+
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
+
+	return
+}
+
+// Unwrap returns the decoded error the original error wrapped, if any.
+//
+// Returns:
+//   - cause (error): the wrapped error, or nil
+func (e *externalError) Unwrap() (cause error) {
+	cause = e.cause
+
+	return
+}
+
+// Envelope carries an error across process boundaries as JSON.
+// Marshaling uses ToJSON with stack traces, and unmarshaling uses FromJSON,
+// so an Envelope embedded in a request or response body round-trips the
+// error's types, fields and stacks.
+//
+// Fields:
+//   - Err (error): the carried error (nil marshals to JSON null)
+type Envelope struct {
+	Err error
+}
+
+// MarshalJSON implements json.Marshaler, encoding the error as produced by ToJSON with FormatWithTrace.
+//
+// Returns:
+//   - data ([]byte): the JSON encoding
+//   - err (error): any error from encoding
+func (e Envelope) MarshalJSON() (data []byte, err error) {
+	data, err = json.Marshal(ToJSON(e.Err, FormatWithTrace()))
+
+	return
+}
+
+// UnmarshalJSON implements json.Unmarshaler, rebuilding the error with FromJSON.
+//
+// Parameters:
+//   - data ([]byte): the JSON encoding, as produced by MarshalJSON or ToJSONString
+//
+// Returns:
+//   - err (error): any error from decoding the JSON document
+func (e *Envelope) UnmarshalJSON(data []byte) (err error) {
+	var formated map[string]any
+
+	if err = json.Unmarshal(data, &formated); err != nil {
+		return
+	}
+
+	e.Err = FromJSON(formated)
+
+	return
+}
+
+// FromJSON rebuilds an error from the map produced by ToJSON.
+// It is the inverse of ToJSON and expects its default ordering: outer wraps first
+// in "chain" and most recent call first in each "stack".
+//
+// The rebuilt errors are *root, *wrapped and *joined values marked as remote (see IsRemote),
+// carrying their Type, Fields and already-resolved Stack, so Is, As, Type() and
+// Formatter behave as on the originating side. An "external" entry, and each external
+// wrapper in "chain", is rebuilt as an opaque error holding the original message.
+//
+// Fields decoded from a JSON document hold JSON types (e.g. numbers become float64).
+//
+// Parameters:
+//   - formated (map[string]any): the map produced by ToJSON, or decoded from its JSON encoding
+//
+// Returns:
+//   - err (error): the rebuilt error, or nil if formated is empty
+func FromJSON(formated map[string]any) (err error) {
+	if len(formated) == 0 {
+		return
+	}
+
+	if errs, ok := formated["errors"]; ok && formated["type"] == "joined" {
+		e := &joined{
+			isRemote: true,
+			frames:   decodeStack(formated["join_stack"]),
+		}
+
+		e.message, _ = formated["message"].(string)
+		e.fields, _ = formated["fields"].(map[string]any)
+
+		if t, ok := formated["join_type"].(string); ok {
+			e.errType = Type(t)
+		}
+
+		for _, item := range decodeList(errs) {
+			if decoded := FromJSON(item); decoded != nil {
+				e.errors = append(e.errors, decoded)
+			}
+		}
+
+		err = e
+
+		return
+	}
+
+	if external, ok := formated["external"].(map[string]any); ok {
+		e := &externalError{}
+
+		e.message, _ = external["message"].(string)
+		e.goType, _ = external["go_type"].(string)
+
+		err = e
+	}
+
+	if join, ok := formated["joined"].(map[string]any); ok {
+		err = FromJSON(join)
+	}
+
+	if part, ok := formated["root"].(map[string]any); ok {
+		e := &root{
+			isRemote: true,
+			cause:    err,
+		}
+
+		e.message, e.errType, e.fields, e.frames, e.isFormatted = decodePart(part)
+
+		err = e
+	}
+
+	chain := decodeList(formated["chain"])
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if goType, ok := chain[i]["go_type"].(string); ok {
+			e := &externalError{
+				goType: goType,
+				cause:  err,
+			}
+
+			e.message, _ = chain[i]["message"].(string)
+
+			err = e
+
+			continue
+		}
+
+		e := &wrapped{
+			isRemote: true,
+			cause:    err,
+		}
+
+		e.message, e.errType, e.fields, e.frames, e.isFormatted = decodePart(chain[i])
+
+		err = e
+	}
+
+	return
+}
+
+// FromJSONString rebuilds an error from the JSON string produced by ToJSONString.
+// It decodes the document and delegates to FromJSON.
+//
+// Parameters:
+//   - data (string): the JSON string to decode
+//
+// Returns:
+//   - decoded (error): the rebuilt error, or nil if data encodes an empty object or null
+//   - err (error): any error from decoding the JSON document
+func FromJSONString(data string) (decoded, err error) {
+	var formated map[string]any
+
+	if err = json.Unmarshal([]byte(data), &formated); err != nil {
+		return
+	}
+
+	decoded = FromJSON(formated)
+
+	return
+}
+
+// IsRemote reports whether err was rebuilt by FromJSON rather than created locally.
+// Only err itself is checked; errors wrapping a remote error locally are not remote.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - remote (bool): true if err was decoded from JSON
+func IsRemote(err error) (remote bool) {
+	switch e := err.(type) {
+	case *root:
+		remote = e.isRemote
+	case *wrapped:
+		remote = e.isRemote
+	case *joined:
+		remote = e.isRemote
+	case *externalError:
+		remote = true
+	}
+
+	return
+}
+
+// decodePart extracts the content of a single "root" or "chain" entry produced by formatPartJSON.
+//
+// Parameters:
+//   - part (map[string]any): the entry to decode
+//
+// Returns:
+//   - message (string): the error message
+//   - errType (Type): the error type, or empty if untyped
+//   - fields (map[string]any): the error fields, or nil if none
+//   - frames (Stack): the resolved stack frames, or nil if none
+//   - formatted (bool): true if the message already embeds the cause's message (see Errorf)
+func decodePart(part map[string]any) (message string, errType Type, fields map[string]any, frames Stack, formatted bool) {
+	message, _ = part["message"].(string)
+
+	if t, ok := part["type"].(string); ok {
+		errType = Type(t)
+	}
+
+	fields, _ = part["fields"].(map[string]any)
+	frames = decodeStack(part["stack"])
+	formatted, _ = part["formatted"].(bool)
+
+	return
+}
+
+// decodeStack converts a list of {"function", "file", "line"} entries into a Stack.
+//
+// Parameters:
+//   - v (any): the list of frame entries
+//
+// Returns:
+//   - frames (Stack): the resolved frames, or nil if v holds none
+func decodeStack(v any) (frames Stack) {
+	for _, item := range decodeList(v) {
+		frame := StackFrame{}
+
+		frame.Name, _ = item["function"].(string)
+		frame.File, _ = item["file"].(string)
+
+		switch line := item["line"].(type) {
+		case int:
+			frame.Line = line
+		case float64:
+			frame.Line = int(line)
+		case json.Number:
+			n, _ := line.Int64()
+
+			frame.Line = int(n)
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return
+}
+
+// decodeList normalizes a list of JSON objects, as built by the formatter ([]map[string]any)
+// or decoded by encoding/json ([]any), into a []map[string]any. Non-object items are skipped.
+//
+// Parameters:
+//   - v (any): the list to normalize
+//
+// Returns:
+//   - items ([]map[string]any): the objects in the list
+func decodeList(v any) (items []map[string]any) {
+	switch x := v.(type) {
+	case []map[string]any:
+		items = x
+	case []any:
+		for _, item := range x {
+			if m, ok := item.(map[string]any); ok {
+				items = append(items, m)
+			}
+		}
+	}
+
+	return
+}
+
+// goType returns the Go type name reported for an external error.
+// Decoded external errors report the type name of the original error.
+//
+// Parameters:
+//   - err (error): the external error
+//
+// Returns:
+//   - name (string): the Go type name
+func goType(err error) (name string) {
+	if e, ok := err.(*externalError); ok && e.goType != "" {
+		name = e.goType
+
+		return
+	}
+
+	name = fmt.Sprintf("%T", err)
+
+	return
+}
@@ -0,0 +1,172 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip chain", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("root", WithType("ROOT_TYPE"), WithField("key", "value"))
+		err = Wrap(err, "wrapper1")
+		err = Wrap(err, "wrapper2", WithType("WRAP_TYPE"))
+
+		decoded, decodeErr := FromJSONString(ToJSONString(err, FormatWithTrace()))
+
+		require.NoError(t, decodeErr)
+		require.Error(t, decoded)
+
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.Equal(t, Type("WRAP_TYPE"), decoded.(Error).Type())
+		assert.Equal(t, ToString(err, FormatWithTrace()), ToString(decoded, FormatWithTrace()))
+		assert.Equal(t, ToJSONString(err, FormatWithTrace()), ToJSONString(decoded, FormatWithTrace()))
+
+		assert.True(t, Is(decoded, New("root", WithType("ROOT_TYPE"))))
+
+		var r *root
+
+		require.True(t, As(decoded, &r))
+		assert.Equal(t, map[string]any{"key": "value"}, r.Fields())
+		assert.True(t, IsRemote(r))
+	})
+
+	t.Run("round trip external", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(errors.New("external"), "wrapper")
+
+		decoded := FromJSON(ToJSON(err, FormatWithTrace()))
+
+		require.Error(t, decoded)
+		assert.Equal(t, "wrapper: external", decoded.Error())
+		assert.Equal(t, ToJSON(err, FormatWithTrace()), ToJSON(decoded, FormatWithTrace()))
+	})
+
+	t.Run("round trip errorf", func(t *testing.T) {
+		t.Parallel()
+
+		for _, err := range []error{
+			Errorf("read cfg: %w", io.EOF),
+			Errorf("read cfg: %w", New("base")),
+			Wrap(Errorf("%w (while reading)", io.EOF), "loading"),
+		} {
+			decoded, decodeErr := FromJSONString(ToJSONString(err, FormatWithTrace()))
+
+			require.NoError(t, decodeErr)
+			assert.Equal(t, err.Error(), decoded.Error())
+			assert.Equal(t, ToJSONString(err, FormatWithTrace()), ToJSONString(decoded, FormatWithTrace()))
+		}
+
+		assert.Equal(t, map[string]any{"message": "read cfg: EOF", "formatted": true}, ToJSON(Errorf("read cfg: %w", io.EOF))["root"])
+		assert.NotContains(t, ToJSON(New("read cfg"))["root"], "formatted")
+	})
+
+	t.Run("round trip joined", func(t *testing.T) {
+		t.Parallel()
+
+		err := Join(New("error1", WithType("TYPE1")), Wrap(New("error2"), "wrapper"))
+
+		decoded, decodeErr := FromJSONString(ToJSONString(err, FormatWithTrace()))
+
+		require.NoError(t, decodeErr)
+		require.Error(t, decoded)
+
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.Equal(t, ToString(err, FormatWithTrace()), ToString(decoded, FormatWithTrace()))
+		assert.True(t, IsRemote(decoded))
+		assert.True(t, Is(decoded, New("error1", WithType("TYPE1"))))
+	})
+
+	t.Run("round trip external wrapper and nested join", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(fmt.Errorf("context: %w", Join(New("error1", WithType("TYPE1")), Wrap(New("error2"), "wrapper"))), "outer")
+
+		decoded, decodeErr := FromJSONString(ToJSONString(err, FormatWithTrace()))
+
+		require.NoError(t, decodeErr)
+		require.Error(t, decoded)
+
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.Equal(t, ToString(err, FormatWithTrace()), ToString(decoded, FormatWithTrace()))
+		assert.Equal(t, ToJSONString(err, FormatWithTrace()), ToJSONString(decoded, FormatWithTrace()))
+		assert.True(t, Is(decoded, New("error1", WithType("TYPE1"))))
+	})
+
+	t.Run("round trip join with type and fields", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(JoinWith([]error{New("error1"), New("error2")}, WithType("BATCH"), WithField("batch_id", "b-1")), "outer")
+
+		decoded, decodeErr := FromJSONString(ToJSONString(err, FormatWithTrace()))
+
+		require.NoError(t, decodeErr)
+		require.Error(t, decoded)
+
+		assert.Equal(t, ToJSONString(err, FormatWithTrace()), ToJSONString(decoded, FormatWithTrace()))
+		assert.ErrorIs(t, decoded, TypeMatcher("BATCH"))
+
+		value, ok := Field[string](decoded, "batch_id")
+
+		assert.True(t, ok)
+		assert.Equal(t, "b-1", value)
+	})
+
+	t.Run("local errors are not remote", func(t *testing.T) {
+		t.Parallel()
+
+		decoded := FromJSON(ToJSON(New("root")))
+
+		assert.True(t, IsRemote(decoded))
+		assert.False(t, IsRemote(Wrap(decoded, "local")))
+		assert.False(t, IsRemote(New("root")))
+		assert.False(t, IsRemote(errors.New("external")))
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, FromJSON(nil))
+
+		decoded, err := FromJSONString("null")
+
+		require.NoError(t, err)
+		assert.NoError(t, decoded)
+
+		_, err = FromJSONString("{")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	type response struct {
+		Error Envelope `json:"error"`
+	}
+
+	err := Wrap(New("root", WithType("ROOT_TYPE")), "wrapper")
+
+	data, marshalErr := json.Marshal(response{Error: Envelope{Err: err}})
+
+	require.NoError(t, marshalErr)
+
+	var decoded response
+
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, err.Error(), decoded.Error.Err.Error())
+	assert.True(t, IsRemote(decoded.Error.Err))
+	assert.Equal(t, ToString(err, FormatWithTrace()), ToString(decoded.Error.Err, FormatWithTrace()))
+}
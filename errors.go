@@ -1,7 +1,12 @@
 package errors
 
 import (
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -9,26 +14,40 @@ import (
 // root represents a fundamental error with complete stack trace information.
 // It serves as the base error type in the package and implements the Error interface.
 //
+// Only errType and fields can change after construction, and only through the
+// mutex-guarded SetType and SetField. The message, cause and trace are never
+// modified, so a root can be shared and wrapped from many goroutines.
+//
 // Fields:
 //   - mu (sync.RWMutex): mutex for thread-safe access to modifiable fields
 //   - isGlobal (bool): indicates if error occurred during package initialization
+//   - isFormatted (bool): indicates if message already embeds the cause's message (see Errorf)
+//   - isRemote (bool): indicates if the error was decoded from JSON rather than created locally
 //   - errType (Type): error type for classification (Type)
 //   - message (string): human-readable error message
 //   - fields (map[string]any): additional structured context (key-value pairs)
 //   - cause (error): the underlying error being wrapped (if any)
 //   - trace (*stack): captured call stack information
+//   - frames (Stack): already-resolved stack frames, set instead of trace on decoded errors
+//   - capture (captureOptions): stack capture configuration set by options at creation time
+//   - safe (safety): which parts are safe to show when redacting, set by options at creation time
 type root struct {
-	mu       sync.RWMutex
-	isGlobal bool
-	errType  Type
-	message  string
-	fields   map[string]any
-	cause    error
-	trace    *stack
+	mu          sync.RWMutex
+	isGlobal    bool
+	isFormatted bool
+	isRemote    bool
+	errType     Type
+	message     string
+	fields      map[string]any
+	cause       error
+	trace       *stack
+	frames      Stack
+	capture     captureOptions
+	safe        safety
 }
 
 // Type returns the error's classification type if one was set.
-// It safely reads the errType field.
+// It safely reads the errType field under the read lock.
 //
 // Returns:
 //   - errType (Type): the error's type, or empty string if untyped or receiver is nil
@@ -37,13 +56,17 @@ func (e *root) Type() (errType Type) {
 		return
 	}
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	errType = e.errType
 
 	return
 }
 
 // Error implements the error interface, returning the error message.
-// If the error wraps another error, it combines both messages.
+// If the error wraps another error, it combines both messages, unless the
+// message was formatted with the cause already embedded in it.
 //
 // Returns:
 //   - msg (string): the error message (or "<nil>" if receiver is nil)
@@ -56,29 +79,33 @@ func (e *root) Error() (msg string) {
 
 	msg = e.message
 
-	if e.cause != nil {
+	if e.cause != nil && !e.isFormatted {
 		msg += ": " + e.cause.Error()
 	}
 
 	return
 }
 
-// Fields returns all structured fields attached to the error.
-// The returned map should not be modified directly.
+// Fields returns a copy of all structured fields attached to the error.
+// The copy is taken under the read lock, so it can be modified freely
+// without affecting the error or racing with SetField.
 //
 // Returns:
-//   - fields (map[string]any): all attached fields (may be nil) or nil if receiver is nil
+//   - fields (map[string]any): a copy of all attached fields (may be nil) or nil if receiver is nil
 func (e *root) Fields() (fields map[string]any) {
 	if e == nil {
 		return
 	}
 
-	fields = e.fields
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	fields = maps.Clone(e.fields)
 
 	return
 }
 
-// StackFrames returns the raw PCs (program counters) from the call stack.
+// StackFrames returns a copy of the raw PCs (program counters) from the call stack.
 // These can be used to reconstruct the full stack trace.
 //
 // Returns:
@@ -89,7 +116,30 @@ func (e *root) StackFrames() (frames []uintptr) {
 		return
 	}
 
-	frames = *e.trace
+	frames = slices.Clone(*e.trace)
+
+	return
+}
+
+// Format implements fmt.Formatter, allowing the error to be printed with fmt verbs.
+//   - %s, %v: the error message
+//   - %q: the error message, double-quoted
+//   - %+v: the full error chain with types, fields and stack trace, as produced by ToString with FormatWithTrace
+//
+// Parameters:
+//   - state (fmt.State): the formatter state provided by the fmt package
+//   - verb (rune): the formatting verb
+func (e *root) Format(state fmt.State, verb rune) {
+	format(state, verb, e)
+}
+
+// LogValue implements slog.LogValuer, so slog handlers render the error as a group
+// holding its message and the full error chain with types and fields, as produced by Formatter.LogValue.
+//
+// Returns:
+//   - value (slog.Value): the group value
+func (e *root) LogValue() (value slog.Value) {
+	value = logValue(e)
 
 	return
 }
@@ -101,6 +151,9 @@ func (e *root) StackFrames() (frames []uintptr) {
 //   - Their messages match
 //   - Their messages match exactly (fallback)
 //
+// A TypeMatcher target matches by type only: the error matches if its type is the target
+// or one of its descendants (see RegisterType).
+//
 // Parameters:
 //   - target (error): the error to compare against
 //
@@ -113,8 +166,16 @@ func (e *root) Is(target error) (matches bool) {
 		return
 	}
 
+	if t, ok := target.(typeMatcher); ok {
+		matches = e.Type().IsA(Type(t))
+
+		return
+	}
+
 	if err, ok := target.(*root); ok {
-		matches = (err.errType == "" || e.errType == err.errType) && e.message == err.message
+		targetType := err.Type()
+
+		matches = (targetType == "" || e.Type() == targetType) && e.message == err.message
 
 		return
 	}
@@ -233,26 +294,42 @@ func (e *root) SetField(key string, value any) (err Error) {
 }
 
 // wrapped represents an error that wraps another error with additional context.
-// Unlike root, it only captures a single stack frame (where it was created).
+// Unlike root, it only exposes a single stack frame (where it was created).
+//
+// The call stack at the wrap point is kept as well, so that the wrap point can be
+// merged into a copy of the root's trace when unpacking, instead of mutating the
+// root's trace in place.
 //
 // Fields:
 //   - mu (sync.RWMutex): mutex for thread-safe access to modifiable fields
+//   - isFormatted (bool): indicates if message already embeds the cause's message (see Errorf)
+//   - isRemote (bool): indicates if the error was decoded from JSON rather than created locally
 //   - errType (Type): error type for classification (Type)
 //   - message (string): human-readable error message
 //   - fields (map[string]any): additional structured context (key-value pairs)
 //   - cause (error): underlying error being wrapped
 //   - frame (*frame): stack frame where the wrap occurred
+//   - trace (*stack): captured call stack at the wrap point
+//   - frames (Stack): already-resolved stack frames, set instead of frame on decoded errors
+//   - capture (captureOptions): stack capture configuration set by options at creation time
+//   - safe (safety): which parts are safe to show when redacting, set by options at creation time
 type wrapped struct {
-	mu      sync.RWMutex
-	errType Type
-	message string
-	fields  map[string]any
-	cause   error
-	frame   *frame
+	mu          sync.RWMutex
+	isFormatted bool
+	isRemote    bool
+	errType     Type
+	message     string
+	fields      map[string]any
+	cause       error
+	frame       *frame
+	trace       *stack
+	frames      Stack
+	capture     captureOptions
+	safe        safety
 }
 
 // Type returns the error's classification type if one was set.
-// It safely reads the errType field.
+// It safely reads the errType field under the read lock.
 //
 // Returns:
 //   - errType (Type): the error's type, or empty string if untyped or receiver is nil
@@ -261,13 +338,17 @@ func (e *wrapped) Type() (errType Type) {
 		return
 	}
 
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	errType = e.errType
 
 	return
 }
 
 // Error implements the error interface, returning the error message.
-// If the error wraps another error, it combines both messages.
+// If the error wraps another error, it combines both messages, unless the
+// message was formatted with the cause already embedded in it.
 //
 // Returns:
 //   - msg (string): the error message (or "<nil>" if receiver is nil)
@@ -280,24 +361,28 @@ func (e *wrapped) Error() (msg string) {
 
 	msg = e.message
 
-	if e.cause != nil {
+	if e.cause != nil && !e.isFormatted {
 		msg += ": " + e.cause.Error()
 	}
 
 	return
 }
 
-// Fields returns all structured fields attached to the error.
-// The returned map should not be modified directly.
+// Fields returns a copy of all structured fields attached to the error.
+// The copy is taken under the read lock, so it can be modified freely
+// without affecting the error or racing with SetField.
 //
 // Returns:
-//   - fields (map[string]any): all attached fields (may be nil) or nil if receiver is nil
+//   - fields (map[string]any): a copy of all attached fields (may be nil) or nil if receiver is nil
 func (e *wrapped) Fields() (fields map[string]any) {
 	if e == nil {
 		return
 	}
 
-	fields = e.fields
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	fields = maps.Clone(e.fields)
 
 	return
 }
@@ -308,9 +393,9 @@ func (e *wrapped) Fields() (fields map[string]any) {
 // For wrapped errors, this returns a single-frame stack containing the wrap point.
 //
 // Returns:
-//   - frames ([]uintptr): slice of program counters representing the call stack or nil if receiver is nil
+//   - frames ([]uintptr): slice of program counters representing the call stack or nil if receiver or frame is nil
 func (e *wrapped) StackFrames() (frames []uintptr) {
-	if e == nil {
+	if e == nil || e.frame == nil {
 		return
 	}
 
@@ -319,6 +404,29 @@ func (e *wrapped) StackFrames() (frames []uintptr) {
 	return
 }
 
+// Format implements fmt.Formatter, allowing the error to be printed with fmt verbs.
+//   - %s, %v: the error message
+//   - %q: the error message, double-quoted
+//   - %+v: the full error chain with types, fields and stack trace, as produced by ToString with FormatWithTrace
+//
+// Parameters:
+//   - state (fmt.State): the formatter state provided by the fmt package
+//   - verb (rune): the formatting verb
+func (e *wrapped) Format(state fmt.State, verb rune) {
+	format(state, verb, e)
+}
+
+// LogValue implements slog.LogValuer, so slog handlers render the error as a group
+// holding its message and the full error chain with types and fields, as produced by Formatter.LogValue.
+//
+// Returns:
+//   - value (slog.Value): the group value
+func (e *wrapped) LogValue() (value slog.Value) {
+	value = logValue(e)
+
+	return
+}
+
 // Is implements error equality checking. Two errors are considered equal if:
 //   - Both are nil, or
 //   - They are of the same type (*wrapped), and:
@@ -326,6 +434,9 @@ func (e *wrapped) StackFrames() (frames []uintptr) {
 //   - Their messages match
 //   - Their messages match exactly (fallback)
 //
+// A TypeMatcher target matches by type only: the error matches if its type is the target
+// or one of its descendants (see RegisterType).
+//
 // Parameters:
 //   - target (error): the error to compare against
 //
@@ -338,8 +449,16 @@ func (e *wrapped) Is(target error) (matches bool) {
 		return
 	}
 
+	if t, ok := target.(typeMatcher); ok {
+		matches = e.Type().IsA(Type(t))
+
+		return
+	}
+
 	if err, ok := target.(*wrapped); ok {
-		matches = (err.errType == "" || e.errType == err.errType) && e.message == err.message
+		targetType := err.Type()
+
+		matches = (targetType == "" || e.Type() == targetType) && e.message == err.message
 
 		return
 	}
@@ -459,24 +578,82 @@ func (e *wrapped) SetField(key string, value any) (err Error) {
 
 // joined represents a collection of multiple errors joined into one.
 // It captures a stack trace at the join point and implements multi-error unwrapping.
+// Like root, it can carry its own type and fields, guarded by the mutex.
 //
 // Fields:
+//   - mu (sync.RWMutex): mutex for thread-safe access to modifiable fields
 //   - isGlobal (bool): indicates if the join occurred during package initialization
+//   - isRemote (bool): indicates if the error was decoded from JSON rather than created locally
+//   - errType (Type): error type for classification of the joined error itself
+//   - message (string): optional message describing the joined errors (see Errorf)
+//   - fields (map[string]any): additional structured context of the joined error itself
 //   - errors ([]error): the list of joined errors
 //   - trace (*stack): captured call stack at the join point
+//   - frames (Stack): already-resolved stack frames, set instead of trace on decoded errors
+//   - capture (captureOptions): stack capture configuration set by options at creation time
+//   - join (joinOptions): flattening and deduplication set by options at creation time
+//   - safe (safety): which parts are safe to show when redacting, set by options at creation time
 type joined struct {
+	mu       sync.RWMutex
 	isGlobal bool
+	isRemote bool
+	errType  Type
+	message  string
+	fields   map[string]any
 	errors   []error
 	trace    *stack
+	frames   Stack
+	capture  captureOptions
+	join     joinOptions
+	safe     safety
+}
+
+// joinOptions holds the join-specific configuration set by WithFlatten and WithDeduplicate.
+//
+// Fields:
+//   - flatten (bool): whether nested joined errors are replaced by their errors
+//   - deduplicate (bool): whether duplicate errors are dropped
+type joinOptions struct {
+	flatten     bool
+	deduplicate bool
+}
+
+// Type returns the joined error's own classification type if one was set.
+// It does not reflect the types of the joined errors.
+//
+// Returns:
+//   - errType (Type): the error's type, or empty string if untyped or receiver is nil
+func (e *joined) Type() (errType Type) {
+	if e == nil {
+		return
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	errType = e.errType
+
+	return
 }
 
 // Error implements the error interface by joining all error messages with newlines.
+// If a message was set (see Errorf), it is returned as is instead.
 // If there are no errors, it returns an empty string.
 //
 // Returns:
 //   - msg (string): concatenated error messages separated by newlines or empty if receiver is nil or no errors
 func (e *joined) Error() (msg string) {
-	if e == nil || len(e.errors) == 0 {
+	if e == nil {
+		return
+	}
+
+	if e.message != "" {
+		msg = e.message
+
+		return
+	}
+
+	if len(e.errors) == 0 {
 		return
 	}
 
@@ -493,7 +670,76 @@ func (e *joined) Error() (msg string) {
 	return
 }
 
-// StackFrames returns the raw program counters from the call stack at the join point.
+// Fields returns a copy of the structured fields attached to the joined error itself.
+// It does not include the fields of the joined errors.
+//
+// Returns:
+//   - fields (map[string]any): a copy of all attached fields (may be nil) or nil if receiver is nil
+func (e *joined) Fields() (fields map[string]any) {
+	if e == nil {
+		return
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	fields = maps.Clone(e.fields)
+
+	return
+}
+
+// SetType associates a type with the joined error itself.
+// The operation is thread-safe, protected by the mutex.
+//
+// Parameters:
+//   - errType (Type): the Type to assign to this error
+//
+// Returns:
+//   - err (Error): the modified error (supports method chaining) or nil if receiver is nil
+func (e *joined) SetType(errType Type) (err Error) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errType = errType
+
+	err = e
+
+	return
+}
+
+// SetField adds a key-value pair to the joined error's own structured context.
+// The operation is thread-safe, protected by the mutex.
+//
+// Parameters:
+//   - key (string): field name (should be descriptive and consistent)
+//   - value (any): field value (any serializable type)
+//
+// Returns:
+//   - err (Error): the modified error (supports method chaining) or nil if receiver is nil
+func (e *joined) SetField(key string, value any) (err Error) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.fields == nil {
+		e.fields = map[string]any{}
+	}
+
+	e.fields[key] = value
+
+	err = e
+
+	return
+}
+
+// StackFrames returns a copy of the raw program counters from the call stack at the join point.
 //
 // Returns:
 //   - frames ([]uintptr): slice of program counters representing the call stack or nil if receiver or trace is nil
@@ -502,18 +748,62 @@ func (e *joined) StackFrames() (frames []uintptr) {
 		return
 	}
 
-	frames = *e.trace
+	frames = slices.Clone(*e.trace)
+
+	return
+}
+
+// stackFrames returns the resolved stack frames at the join point.
+// Decoded errors carry their frames already resolved; local ones are resolved from trace.
+//
+// Returns:
+//   - frames (Stack): the resolved frames, or nil if none were captured
+func (e *joined) stackFrames() (frames Stack) {
+	if e.frames != nil {
+		frames = e.frames
+
+		return
+	}
+
+	if e.trace != nil {
+		frames = e.trace.resolveToStackFrames()
+	}
+
+	return
+}
+
+// Format implements fmt.Formatter, allowing the error to be printed with fmt verbs.
+//   - %s, %v: the error message
+//   - %q: the error message, double-quoted
+//   - %+v: the full list of joined errors with their chains and the join location, as produced by ToString with FormatWithTrace
+//
+// Parameters:
+//   - state (fmt.State): the formatter state provided by the fmt package
+//   - verb (rune): the formatting verb
+func (e *joined) Format(state fmt.State, verb rune) {
+	format(state, verb, e)
+}
+
+// LogValue implements slog.LogValuer, so slog handlers render the error as a group
+// holding its message and the joined errors with types and fields, as produced by Formatter.LogValue.
+//
+// Returns:
+//   - value (slog.Value): the group value
+func (e *joined) LogValue() (value slog.Value) {
+	value = logValue(e)
 
 	return
 }
 
 // Is checks if any of the joined errors match the target using the Is function.
+// A TypeMatcher target also matches if the joined error's own type is the target or one
+// of its descendants (see RegisterType).
 //
 // Parameters:
 //   - target (error): the error to compare against
 //
 // Returns:
-//   - matches (bool): true if any joined error matches the target
+//   - matches (bool): true if the joined error or any joined error matches the target
 func (e *joined) Is(target error) (matches bool) {
 	if target == nil {
 		matches = e == nil
@@ -521,6 +811,12 @@ func (e *joined) Is(target error) (matches bool) {
 		return
 	}
 
+	if t, ok := target.(typeMatcher); ok && e.Type() != "" && e.Type().IsA(Type(t)) {
+		matches = true
+
+		return
+	}
+
 	for _, err := range e.errors {
 		if Is(err, target) {
 			matches = true
@@ -583,7 +879,9 @@ type Error interface {
 }
 
 // Type represents a classification type for errors.
-// Types allow errors to be categorized and handled based on their kind.
+// Types allow errors to be categorized and handled based on their kind,
+// and can be organized in a hierarchy with RegisterType. A Type is not an error:
+// match it with IsType, or with Is through TypeMatcher.
 type Type string
 
 // OptionFunc represents a function that can configure an Error.
@@ -593,16 +891,26 @@ type OptionFunc func(err Error)
 var (
 	_ Error = (*root)(nil)
 	_ Error = (*wrapped)(nil)
-	_ error = (*joined)(nil)
+	_ Error = (*joined)(nil)
+	_ error = typeMatcher("")
+
+	_ fmt.Formatter = (*root)(nil)
+	_ fmt.Formatter = (*wrapped)(nil)
+	_ fmt.Formatter = (*joined)(nil)
+
+	_ slog.LogValuer = (*root)(nil)
+	_ slog.LogValuer = (*wrapped)(nil)
+	_ slog.LogValuer = (*joined)(nil)
 )
 
 // New creates a new root error with stack trace information.
 // The skip parameter (3) ensures the trace starts at the caller's location.
 //
 // The creation process:
-//  1. Captures the call stack skipping internal frames.
-//  2. Checks if the error occurred during global initialization.
-//  3. Applies all provided option functions to configure the error.
+//  1. Applies all provided option functions to configure the error.
+//  2. Captures the call stack skipping internal frames, unless disabled for the error
+//     (see WithoutStack, SetStackCapture and SetStackCaptureForType).
+//  3. Checks if the error occurred during global initialization.
 //
 // Parameters:
 //   - msg (string): the primary error message
@@ -611,18 +919,18 @@ var (
 // Returns:
 //   - err (error): the newly created error (implements Error interface)
 func New(msg string, ofs ...OptionFunc) (err error) {
-	trace := callers(3) // callers(3) skips this method (New), callers, and runtime.Callers
-
 	e := &root{
-		isGlobal: trace.isGlobal(),
-		message:  msg,
-		trace:    trace,
+		message: msg,
 	}
 
 	for _, f := range ofs {
 		f(e)
 	}
 
+	e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (New), callers, and runtime.Callers
+
+	notify(EventNew, e)
+
 	err = e
 
 	return
@@ -631,7 +939,7 @@ func New(msg string, ofs ...OptionFunc) (err error) {
 // Wrap creates a new error that wraps an existing error with additional context.
 // The new error will have its own stack frame while preserving the original's trace.
 //
-// It delegates to the internal wrap function and applies options afterward.
+// It delegates to the internal wrap function, which applies the options.
 //
 // Parameters:
 //   - cause (error): the error to wrap
@@ -639,12 +947,69 @@ func New(msg string, ofs ...OptionFunc) (err error) {
 //   - ofs (...OptionFunc): configuration options (same as New)
 //
 // Returns:
-//   - err (error): the new wrapping error
+//   - err (error): the new wrapping error, or nil if cause is nil
 func Wrap(cause error, msg string, ofs ...OptionFunc) (err error) {
-	w := wrap(cause, msg)
+	w := wrap(cause, msg, ofs)
+	if w == nil {
+		return
+	}
+
+	err = w
+
+	return
+}
+
+// Newf creates a new root error with a formatted message and stack trace information.
+// It behaves like New, with the message built by fmt.Sprintf from format and args.
+//
+// Any OptionFunc values found in args are not used as format operands; they are
+// applied to the error instead, so Newf("read %s", path, WithType("IO")) is valid.
+//
+// Parameters:
+//   - format (string): the format specifier for the error message
+//   - args (...any): format operands, optionally mixed with OptionFunc values
+//
+// Returns:
+//   - err (error): the newly created error (implements Error interface)
+func Newf(format string, args ...any) (err error) {
+	operands, ofs := splitArgs(args)
+
+	e := &root{
+		message: fmt.Sprintf(format, operands...),
+	}
 
 	for _, f := range ofs {
-		f(w)
+		f(e)
+	}
+
+	e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (Newf), callers, and runtime.Callers
+
+	notify(EventNew, e)
+
+	err = e
+
+	return
+}
+
+// Wrapf creates a new error that wraps an existing error with a formatted context message.
+// It behaves like Wrap, with the message built by fmt.Sprintf from format and args.
+//
+// Any OptionFunc values found in args are applied to the new error rather than
+// being used as format operands.
+//
+// Parameters:
+//   - cause (error): the error to wrap
+//   - format (string): the format specifier for the context message
+//   - args (...any): format operands, optionally mixed with OptionFunc values
+//
+// Returns:
+//   - err (error): the new wrapping error, or nil if cause is nil
+func Wrapf(cause error, format string, args ...any) (err error) {
+	operands, ofs := splitArgs(args)
+
+	w := wrap(cause, fmt.Sprintf(format, operands...), ofs)
+	if w == nil {
+		return
 	}
 
 	err = w
@@ -652,17 +1017,132 @@ func Wrap(cause error, msg string, ofs ...OptionFunc) (err error) {
 	return
 }
 
+// Errorf creates a new error from a format specifier, like fmt.Errorf, with stack trace information.
+// The %w verb is supported, and the operands it formats become the error's causes:
+//
+//   - No %w operand, or a nil one: a root error, as created by Newf.
+//   - One %w operand: the operand is wrapped, as by Wrap, and reachable via Unwrap.
+//   - Several %w operands: a joined error of the operands, reachable via Unwrap() []error.
+//
+// In every case Error() returns the formatted message as is, without the causes'
+// messages appended a second time.
+//
+// Any OptionFunc values found in args are applied to the new error rather than
+// being used as format operands. When several %w operands produce a joined error,
+// they set the type and fields of the joined error itself.
+//
+// Parameters:
+//   - format (string): the format specifier for the error message
+//   - args (...any): format operands, optionally mixed with OptionFunc values
+//
+// Returns:
+//   - err (error): the newly created error
+func Errorf(format string, args ...any) (err error) {
+	operands, ofs := splitArgs(args)
+
+	formatted := fmt.Errorf(format, operands...)
+	msg := formatted.Error()
+
+	switch x := formatted.(type) {
+	case interface{ Unwrap() error }:
+		// a nil %w operand leaves nothing to wrap, so it falls back to a root error, as fmt.Errorf does
+		if cause := x.Unwrap(); cause != nil {
+			err = wrap(cause, msg, append([]OptionFunc{withFormatted()}, ofs...))
+
+			return
+		}
+	case interface{ Unwrap() []error }:
+		e := &joined{
+			message: msg,
+			errors:  x.Unwrap(),
+		}
+
+		for _, f := range ofs {
+			f(e)
+		}
+
+		e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (Errorf), callers, and runtime.Callers
+
+		notify(EventJoin, e)
+
+		err = e
+
+		return
+	}
+
+	e := &root{
+		message: msg,
+	}
+
+	for _, f := range ofs {
+		f(e)
+	}
+
+	e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (Errorf), callers, and runtime.Callers
+
+	notify(EventNew, e)
+
+	err = e
+
+	return
+}
+
+// splitArgs separates OptionFunc values from format operands.
+// It lets Newf, Wrapf and Errorf accept options alongside their format arguments.
+//
+// Parameters:
+//   - args ([]any): format operands, optionally mixed with OptionFunc values
+//
+// Returns:
+//   - operands ([]any): the arguments to pass to the fmt package, in their original order
+//   - ofs ([]OptionFunc): the option functions found in args, in their original order
+func splitArgs(args []any) (operands []any, ofs []OptionFunc) {
+	operands = make([]any, 0, len(args))
+
+	for _, arg := range args {
+		if f, ok := arg.(OptionFunc); ok {
+			ofs = append(ofs, f)
+
+			continue
+		}
+
+		operands = append(operands, arg)
+	}
+
+	return
+}
+
+// withFormatted creates an OptionFunc that marks the message of the error being created as
+// already embedding the cause's message (see Errorf), so Error() does not append it again.
+//
+// Returns:
+//   - f (OptionFunc): configuration function for wrap
+func withFormatted() (f OptionFunc) {
+	return func(err Error) {
+		switch e := err.(type) {
+		case *root:
+			e.isFormatted = true
+		case *wrapped:
+			e.isFormatted = true
+		}
+	}
+}
+
 // wrap is the internal implementation of error wrapping logic that handles three distinct cases:
 //
 // 1. Wrapping a root (preserves full stack trace while adding new context)
-// 2. Wrapping a wrapped (finds root error to preserve complete trace)
+// 2. Wrapping a wrapped (the root further down the chain keeps its trace)
 // 3. Wrapping a non-package error (creates new root error with full stack)
 //
 // The wrapping process:
-//  1. Captures the current stack trace and frame.
-//  2. Handles root by inserting the new trace or recreating if global.
-//  3. For wrapped, inserts into the underlying root's trace.
-//  4. For other errors, creates a new root.
+//  1. Creates the wrapping error and applies the option functions to it.
+//  2. Captures the current stack trace and frame, unless disabled for the error.
+//  3. Handles root by recreating it if global, so the trace points at the wrap site.
+//  4. For other package errors, leaves the cause untouched.
+//
+// The cause is never modified: the captured trace is stored on the new wrapped error
+// and merged into a copy of the root's trace by Unpack, so concurrent wraps of the
+// same error are safe.
 //
 // Parameters:
 //   - cause (error): The error being wrapped. Must be non-nil for the function to have effect.
@@ -670,51 +1150,68 @@ func Wrap(cause error, msg string, ofs ...OptionFunc) (err error) {
 //   - msg (string): Additional contextual information describing the wrapping site.
 //     This message will become part of the error chain and appear in the Error() output.
 //     Should be descriptive enough to identify where/why the wrap occurred.
+//   - ofs ([]OptionFunc): configuration options applied to the new error before capture
 //
 // Returns:
 //   - err (Error): The newly created wrapping error that implements the Error interface.
-func wrap(cause error, msg string) (err Error) {
+func wrap(cause error, msg string, ofs []OptionFunc) (err Error) {
 	if cause == nil {
 		return
 	}
 
-	trace := callers(4) // callers(4) skips runtime.Callers, callers, this method (wrap), and Wrap
-	frame := caller(3)  // caller(3) skips caller, this method (wrap), and Wrap
-
-	switch e := cause.(type) {
-	case *root:
-		if e.isGlobal {
-			cause = &root{
-				isGlobal: e.isGlobal,
-				errType:  e.errType,
-				message:  e.message,
-				fields:   e.fields,
-				cause:    e.cause,
-				trace:    trace,
-			}
-		} else {
-			e.trace.insertPC(*trace)
-		}
-	case *wrapped:
-		if r, ok := Cause(cause).(*root); ok {
-			r.trace.insertPC(*trace)
-		}
+	switch cause.(type) {
+	case *root, *wrapped:
 	default:
-		err = &root{
+		e := &root{
 			message: msg,
-			cause:   e,
-			trace:   trace,
+			cause:   cause,
 		}
 
+		for _, f := range ofs {
+			f(e)
+		}
+
+		e.trace, _ = e.capture.callers(4, e.errType) // callers(4) skips runtime.Callers, callers, this method (wrap), and Wrap
+
+		notify(EventWrap, e)
+
+		err = e
+
 		return
 	}
 
-	err = &wrapped{
+	w := &wrapped{
 		message: msg,
 		cause:   cause,
-		frame:   frame,
 	}
 
+	for _, f := range ofs {
+		f(w)
+	}
+
+	w.trace, _ = w.capture.callers(4, w.errType) // callers(4) skips runtime.Callers, callers, this method (wrap), and Wrap
+	w.frame = w.capture.caller(3, w.errType)     // caller(3) skips caller, this method (wrap), and Wrap
+
+	if e, ok := cause.(*root); ok && e.isGlobal {
+		w.cause = &root{
+			isGlobal:    e.isGlobal,
+			isFormatted: e.isFormatted,
+			errType:     e.Type(),
+			message:     e.message,
+			fields:      e.Fields(),
+			cause:       e.cause,
+			trace:       w.trace,
+			safe:        e.safe,
+		}
+
+		// the new root's trace already ends at the wrap point, there is nothing left to merge
+		w.trace = nil
+	}
+
+	notify(EventWrap, w)
+
+	err = w
+
 	return
 }
 
@@ -745,6 +1242,71 @@ func WithField(key string, value any) (f OptionFunc) {
 	}
 }
 
+// WithCallerSkip creates an OptionFunc that skips additional frames when capturing the stack.
+// It is meant for helper functions that create errors on behalf of their callers,
+// so the trace starts at the helper's caller rather than inside the helper.
+//
+// Parameters:
+//   - skip (int): number of additional frames to skip
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithCallerSkip(skip int) (f OptionFunc) {
+	return func(err Error) {
+		if o := captureOptionsOf(err); o != nil {
+			o.skip += skip
+		}
+	}
+}
+
+// WithStackDepth creates an OptionFunc that sets the maximum number of frames captured
+// for the error, overriding the package default set by SetStackDepth.
+//
+// Parameters:
+//   - depth (int): maximum number of frames to capture
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithStackDepth(depth int) (f OptionFunc) {
+	return func(err Error) {
+		if o := captureOptionsOf(err); o != nil {
+			o.depth = depth
+		}
+	}
+}
+
+// WithoutStack creates an OptionFunc that disables stack capture for the error.
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithoutStack() (f OptionFunc) {
+	return func(err Error) {
+		if o := captureOptionsOf(err); o != nil {
+			o.disabled = true
+		}
+	}
+}
+
+// captureOptionsOf returns the stack capture configuration of an error being created.
+//
+// Parameters:
+//   - err (Error): the error being configured
+//
+// Returns:
+//   - options (*captureOptions): the error's capture configuration, or nil for other error types
+func captureOptionsOf(err Error) (options *captureOptions) {
+	switch e := err.(type) {
+	case *root:
+		options = &e.capture
+	case *wrapped:
+		options = &e.capture
+	case *joined:
+		options = &e.capture
+	}
+
+	return
+}
+
 // Unwrap returns the result of calling Unwrap() on err if available.
 // Matches the behavior of errors.Unwrap in the standard library.
 //
@@ -800,38 +1362,19 @@ func Is(err, target error) (matches bool) {
 // Returns:
 //   - matches (bool): true if a match is found
 func is(err, target error, isComparable bool) (matches bool) {
-	for {
+	Walk(err, func(err error, _ int, _ []int) (more bool) {
 		if isComparable && err == target {
 			matches = true
-
-			return
-		}
-
-		if x, k := err.(interface{ Is(error) bool }); k && x.Is(target) {
+		} else if x, k := err.(interface{ Is(error) bool }); k && x.Is(target) {
 			matches = true
-
-			return
 		}
 
-		switch x := err.(type) {
-		case interface{ Unwrap() error }:
-			if err = x.Unwrap(); err == nil {
-				return
-			}
-		case interface{ Unwrap() []error }:
-			for _, err := range x.Unwrap() {
-				if is(err, target, isComparable) {
-					matches = true
+		more = !matches
 
-					return
-				}
-			}
+		return
+	})
 
-			return
-		default:
-			return
-		}
-	}
+	return
 }
 
 // As searches err's chain for an error assignable to target and sets target if found.
@@ -880,44 +1423,121 @@ func As(err error, target any) (ok bool) {
 // Returns:
 //   - ok (bool): true if a match is found and target is set
 func as(err error, target any, targetVal reflect.Value, targetType reflect.Type) (ok bool) {
-	for {
+	Walk(err, func(err error, _ int, _ []int) (more bool) {
 		if reflect.TypeOf(err).AssignableTo(targetType) {
 			targetVal.Elem().Set(reflect.ValueOf(err))
 
 			ok = true
-
-			return
+		} else if x, k := err.(interface{ As(interface{}) bool }); k && x.As(target) {
+			ok = true
 		}
 
-		if x, k := err.(interface{ As(interface{}) bool }); k && x.As(target) {
-			ok = true
+		more = !ok
 
-			return
+		return
+	})
+
+	return
+}
+
+// AsType finds the first error in err's tree that is of type T and returns it.
+// It is the generic form of As and matches exactly the errors As would.
+//
+// Usage:
+//
+//	if e, ok := errors.AsType[*MyError](err); ok {
+//		...
+//	}
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - target (T): the matching error, or the zero value of T if none is found
+//   - ok (bool): true if a matching error was found
+func AsType[T error](err error) (target T, ok bool) {
+	ok = As(err, &target)
+
+	return
+}
+
+// FindAll returns every error in err's tree that is of type T, walking wrapped chains
+// and joined errors in the same order as Is and As (outermost first, joined errors in order).
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - found ([]T): the matching errors, or nil if none is found
+func FindAll[T error](err error) (found []T) {
+	for err := range All(err) {
+		if e, ok := err.(T); ok {
+			found = append(found, e)
+
+			continue
 		}
 
-		switch x := err.(type) {
-		case interface{ Unwrap() error }:
-			if err = x.Unwrap(); err == nil {
-				return
-			}
-		case interface{ Unwrap() []error }:
-			for _, err := range x.Unwrap() {
-				if err == nil {
-					continue
-				}
+		// a joined error's As only delegates to its errors, which are walked next
+		if _, ok := err.(*joined); ok {
+			continue
+		}
 
-				if as(err, target, targetVal, targetType) {
-					ok = true
+		var e T
 
-					return
-				}
-			}
+		if x, ok := err.(interface{ As(interface{}) bool }); ok && x.As(&e) {
+			found = append(found, e)
+		}
+	}
 
-			return
-		default:
-			return
+	return
+}
+
+// Field returns the value of the field key from the first error in err's tree holding it,
+// searching from the outermost error down to the root, and through joined errors in order.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//   - key (string): the field name
+//
+// Returns:
+//   - value (T): the field value, or the zero value of T if the field is missing or not a T
+//   - ok (bool): true if the field was found and holds a T
+func Field[T any](err error, key string) (value T, ok bool) {
+	for err := range All(err) {
+		x, k := err.(interface{ Fields() map[string]any })
+		if !k {
+			continue
 		}
+
+		v, found := x.Fields()[key]
+		if !found {
+			continue
+		}
+
+		value, ok = v.(T)
+
+		return
+	}
+
+	return
+}
+
+// MustField is like Field but panics if the field is missing or does not hold a T.
+// It is meant for fields the program guarantees to be set, such as those added by its own middleware.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//   - key (string): the field name
+//
+// Returns:
+//   - value (T): the field value
+func MustField[T any](err error, key string) (value T) {
+	value, ok := Field[T](err, key)
+	if !ok {
+		panic(fmt.Sprintf("errors: field %q of type %s not found", key, reflect.TypeFor[T]()))
 	}
+
+	return
 }
 
 // Cause returns the underlying root cause of the error by recursively unwrapping.
@@ -953,30 +1573,234 @@ func Cause(err error) (cause error) {
 // Returns:
 //   - err (error): the joined error or single error if only one
 func Join(errs ...error) (err error) {
+	err = join(errs, nil)
+
+	return
+}
+
+// JoinWith combines multiple errors into a single joined error, like Join, configured by options.
+// Besides the options accepted by New, such as WithType and WithField, which apply to the
+// joined error itself, it accepts WithFlatten and WithDeduplicate.
+//
+// If only one error remains after filtering, flattening and deduplication, it is returned
+// directly, unless a type or fields were set, in which case a joined error is still created
+// to carry them.
+//
+// Parameters:
+//   - errs ([]error): the errors to join
+//   - ofs (...OptionFunc): configuration options
+//
+// Returns:
+//   - err (error): the joined error, the single remaining error, or nil if there are none
+func JoinWith(errs []error, ofs ...OptionFunc) (err error) {
+	err = join(errs, ofs)
+
+	return
+}
+
+// WithFlatten creates an OptionFunc that makes JoinWith replace nested joined errors by
+// the errors they join, recursively, so the result is a single flat list. Nested joined
+// errors with their own message, type or fields are kept, as flattening would lose them.
+// It has no effect on other errors.
+//
+// Returns:
+//   - f (OptionFunc): configuration function for JoinWith
+func WithFlatten() (f OptionFunc) {
+	return func(err Error) {
+		if e, ok := err.(*joined); ok {
+			e.join.flatten = true
+		}
+	}
+}
+
+// WithDeduplicate creates an OptionFunc that makes JoinWith drop duplicate errors, keeping
+// the first one. Errors are duplicates if they have the same type, message and root stack
+// trace, e.g. the same error created repeatedly at one place, as in a loop.
+// It has no effect on other errors.
+//
+// Returns:
+//   - f (OptionFunc): configuration function for JoinWith
+func WithDeduplicate() (f OptionFunc) {
+	return func(err Error) {
+		if e, ok := err.(*joined); ok {
+			e.join.deduplicate = true
+		}
+	}
+}
+
+// join is the internal implementation of Join and JoinWith.
+//
+// Parameters:
+//   - errs ([]error): the errors to join
+//   - ofs ([]OptionFunc): configuration options
+//
+// Returns:
+//   - err (error): the joined error, the single remaining error, or nil if there are none
+func join(errs []error, ofs []OptionFunc) (err error) {
+	e := &joined{}
+
+	for _, f := range ofs {
+		f(e)
+	}
+
 	var nonNilErrs []error
 
-	for _, e := range errs {
-		if e != nil {
-			nonNilErrs = append(nonNilErrs, e)
+	for _, x := range errs {
+		if x == nil {
+			continue
+		}
+
+		if e.join.flatten {
+			nonNilErrs = appendFlattened(nonNilErrs, x)
+
+			continue
 		}
+
+		nonNilErrs = append(nonNilErrs, x)
+	}
+
+	if e.join.deduplicate {
+		nonNilErrs = deduplicate(nonNilErrs)
 	}
 
 	if len(nonNilErrs) == 0 {
 		return
 	}
 
-	if len(nonNilErrs) == 1 {
+	if len(nonNilErrs) == 1 && e.errType == "" && len(e.fields) == 0 {
 		err = nonNilErrs[0]
 
 		return
 	}
 
-	trace := callers(3)
+	e.errors = nonNilErrs
+
+	e.trace, e.isGlobal = e.capture.callers(4, e.errType) // callers(4) skips this method (join), Join or JoinWith, callers, and runtime.Callers
+
+	notify(EventJoin, e)
+
+	err = e
+
+	return
+}
+
+// appendFlattened appends err to errs, replacing a joined error without its own message,
+// type or fields by the errors it joins, recursively.
+//
+// Parameters:
+//   - errs ([]error): the errors collected so far
+//   - err (error): the error to append
+//
+// Returns:
+//   - flattened ([]error): errs with err or its joined errors appended
+func appendFlattened(errs []error, err error) (flattened []error) {
+	e, ok := err.(*joined)
+	if !ok || e.message != "" || e.Type() != "" || len(e.Fields()) > 0 {
+		flattened = append(errs, err)
+
+		return
+	}
+
+	flattened = errs
+
+	for _, x := range e.errors {
+		if x != nil {
+			flattened = appendFlattened(flattened, x)
+		}
+	}
+
+	return
+}
+
+// deduplicate returns errs without the errors that duplicate an earlier one,
+// as defined by WithDeduplicate.
+//
+// Parameters:
+//   - errs ([]error): the errors to deduplicate
+//
+// Returns:
+//   - unique ([]error): the first occurrence of each distinct error, in order
+func deduplicate(errs []error) (unique []error) {
+	unique = make([]error, 0, len(errs))
+
+	seen := make(map[dedupeKey]struct{}, len(errs))
+
+	for _, err := range errs {
+		key := dedupeKeyOf(err)
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		unique = append(unique, err)
+	}
+
+	return
+}
+
+// dedupeKey identifies an error for deduplication.
+//
+// Fields:
+//   - errType (Type): the error's type
+//   - message (string): the error's message
+//   - stack (string): the program counters of the root stack trace, as raw bytes
+type dedupeKey struct {
+	errType Type
+	message string
+	stack   string
+}
+
+// dedupeKeyOf computes the deduplication key of err.
+//
+// Parameters:
+//   - err (error): the error to identify
+//
+// Returns:
+//   - key (dedupeKey): err's type, message and root stack trace
+func dedupeKeyOf(err error) (key dedupeKey) {
+	PCs := rootStack(err)
+
+	stack := make([]byte, 0, 8*len(PCs))
+
+	for _, pc := range PCs {
+		stack = binary.LittleEndian.AppendUint64(stack, uint64(pc))
+	}
+
+	key = dedupeKey{errType: typeOf(err), message: err.Error(), stack: string(stack)}
 
-	err = &joined{
-		isGlobal: trace.isGlobal(),
-		errors:   nonNilErrs,
-		trace:    trace,
+	return
+}
+
+// typeOf returns the type of err if it has one.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - errType (Type): the error's type, or empty if err has no Type method
+func typeOf(err error) (errType Type) {
+	if x, ok := err.(interface{ Type() Type }); ok {
+		errType = x.Type()
+	}
+
+	return
+}
+
+// rootStack returns the raw stack of the deepest root error in err's chain. The
+// result shares the root's trace and must not be modified.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - PCs ([]uintptr): the root's program counters, or nil if the chain has no root with a trace
+func rootStack(err error) (PCs []uintptr) {
+	for ; err != nil; err = Unwrap(err) {
+		if r, ok := err.(*root); ok && r.trace != nil {
+			PCs = *r.trace
+		}
 	}
 
 	return
@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -160,6 +162,108 @@ func TestWrapError(t *testing.T) {
 	})
 }
 
+func TestFormattedConstructors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("newf", func(t *testing.T) {
+		t.Parallel()
+
+		err := Newf("open %s: code %d", "config.yaml", 2, WithType("IO"), WithField("key", "value"))
+
+		require.Error(t, err)
+		assert.Equal(t, "open config.yaml: code 2", err.Error())
+		assert.Equal(t, Type("IO"), err.(*root).errType)
+		assert.Equal(t, map[string]interface{}{"key": "value"}, err.(*root).fields)
+		assert.NotEmpty(t, err.(*root).trace)
+	})
+
+	t.Run("wrapf", func(t *testing.T) {
+		t.Parallel()
+
+		baseErr := New("base")
+		wrappedErr := Wrapf(baseErr, "attempt %d", 3, WithType("RETRY"))
+
+		require.Error(t, wrappedErr)
+		assert.Equal(t, "attempt 3: base", wrappedErr.Error())
+		assert.Equal(t, Type("RETRY"), wrappedErr.(*wrapped).errType)
+		assert.Equal(t, baseErr, Unwrap(wrappedErr))
+	})
+
+	t.Run("wrapf nil error", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrapf(nil, "attempt %d", 3, WithType("RETRY"))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("errorf without wrap verb", func(t *testing.T) {
+		t.Parallel()
+
+		err := Errorf("status %d", 500, WithField("key", "value"))
+
+		require.Error(t, err)
+		assert.Equal(t, "status 500", err.Error())
+		assert.Equal(t, map[string]interface{}{"key": "value"}, err.(*root).fields)
+		assert.NotEmpty(t, err.(*root).trace)
+		assert.NoError(t, Unwrap(err))
+	})
+
+	t.Run("errorf wrapping package error", func(t *testing.T) {
+		t.Parallel()
+
+		baseErr := New("base")
+		err := Errorf("read %q: %w", "file", baseErr, WithType("IO"))
+
+		require.Error(t, err)
+		assert.Equal(t, `read "file": base`, err.Error())
+		assert.Equal(t, Type("IO"), err.(*wrapped).errType)
+		assert.Equal(t, baseErr, Unwrap(err))
+		assert.True(t, Is(err, baseErr))
+	})
+
+	t.Run("errorf wrapping external error", func(t *testing.T) {
+		t.Parallel()
+
+		stdErr := errors.New("standard error")
+		err := Errorf("%w (while reading)", stdErr)
+
+		require.Error(t, err)
+		assert.Equal(t, "standard error (while reading)", err.Error())
+		assert.Equal(t, stdErr, Unwrap(err))
+		assert.NotEmpty(t, err.(*root).trace)
+	})
+
+	t.Run("errorf wrapping nil", func(t *testing.T) {
+		t.Parallel()
+
+		err := Errorf("read config: %w", nil, WithType("IO"))
+
+		require.Error(t, err)
+		assert.Equal(t, fmt.Errorf("read config: %w", nil).Error(), err.Error())
+		assert.Equal(t, Type("IO"), err.(*root).errType)
+		assert.NotEmpty(t, err.(*root).trace)
+		assert.NoError(t, Unwrap(err))
+	})
+
+	t.Run("errorf with multiple wrap verbs", func(t *testing.T) {
+		t.Parallel()
+
+		err1 := New("error1")
+		err2 := errors.New("error2")
+
+		err := Errorf("both failed: %w, %w", err1, err2)
+
+		require.Error(t, err)
+		assert.Equal(t, "both failed: error1, error2", err.Error())
+		assert.Equal(t, []error{err1, err2}, err.(*joined).Unwrap())
+		assert.NotEmpty(t, err.(*joined).StackFrames())
+		assert.True(t, Is(err, err1))
+		assert.True(t, Is(err, err2))
+		assert.Contains(t, ToString(err), "both failed: error1, error2\n\nMultiple errors (2):")
+	})
+}
+
 func TestErrorOptions(t *testing.T) {
 	t.Parallel()
 
@@ -281,6 +385,18 @@ func TestStackPreservation(t *testing.T) {
 		assert.Greater(t, len(*rootErr.trace), 1)
 	})
 
+	t.Run("wrapping does not modify original stack", func(t *testing.T) {
+		t.Parallel()
+
+		baseErr := New("base")
+		trace := baseErr.(*root).StackFrames()
+
+		wrappedErr := Wrap(baseErr, "wrapper")
+
+		assert.Equal(t, trace, baseErr.(*root).StackFrames())
+		assert.Greater(t, len(Unpack(wrappedErr).ErrRoot.Stack), len(Unpack(baseErr).ErrRoot.Stack))
+	})
+
 	t.Run("double wrapping", func(t *testing.T) {
 		t.Parallel()
 
@@ -294,6 +410,79 @@ func TestStackPreservation(t *testing.T) {
 	})
 }
 
+func TestConcurrency(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 32
+
+	t.Run("wrap and annotate shared root", func(t *testing.T) {
+		t.Parallel()
+
+		baseErr := New("base", WithField("key", "value"))
+		trace := baseErr.(*root).StackFrames()
+
+		var wg sync.WaitGroup
+
+		for i := range goroutines {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				wrappedErr := Wrapf(baseErr, "wrapper %d", i, WithField("index", i))
+
+				baseErr.(Error).SetType("BASE_TYPE")
+				baseErr.(Error).SetField(fmt.Sprintf("key%d", i), i)
+
+				fields := baseErr.(Error).Fields()
+				fields["mutated"] = true
+
+				_ = baseErr.Error()
+				_ = baseErr.(Error).Type()
+				_ = Is(wrappedErr, baseErr)
+				_ = ToString(wrappedErr, FormatWithTrace())
+				_ = ToJSON(wrappedErr, FormatWithTrace())
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, trace, baseErr.(*root).StackFrames())
+		assert.Equal(t, Type("BASE_TYPE"), baseErr.(Error).Type())
+		assert.Len(t, baseErr.(Error).Fields(), goroutines+1)
+		assert.NotContains(t, baseErr.(Error).Fields(), "mutated")
+	})
+
+	t.Run("wrap and annotate shared wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		wrappedErr := Wrap(New("base"), "wrapper")
+
+		var wg sync.WaitGroup
+
+		for i := range goroutines {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				outerErr := Wrap(wrappedErr, "outer", WithType("OUTER_TYPE"))
+
+				wrappedErr.(Error).SetType("WRAP_TYPE")
+				wrappedErr.(Error).SetField(fmt.Sprintf("key%d", i), i)
+
+				_ = wrappedErr.(Error).Fields()
+				_ = ToString(Join(outerErr, wrappedErr), FormatWithTrace())
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, Type("WRAP_TYPE"), wrappedErr.(Error).Type())
+		assert.Len(t, wrappedErr.(Error).Fields(), goroutines)
+	})
+}
+
 func TestIs(t *testing.T) {
 	t.Parallel()
 
@@ -381,6 +570,106 @@ func TestAs(t *testing.T) {
 	}
 }
 
+func TestAsType(t *testing.T) {
+	t.Parallel()
+
+	rootErr := New("root")
+	wrappedErr := Wrap(rootErr, "wrapper")
+
+	r, ok := AsType[*root](wrappedErr)
+
+	require.True(t, ok)
+	assert.Same(t, rootErr, r)
+
+	w, ok := AsType[*wrapped](Join(errors.New("external"), wrappedErr))
+
+	require.True(t, ok)
+	assert.Same(t, wrappedErr, w)
+
+	e, ok := AsType[Error](wrappedErr)
+
+	require.True(t, ok)
+	assert.Same(t, wrappedErr, e)
+
+	_, ok = AsType[*wrapped](rootErr)
+
+	assert.False(t, ok)
+
+	_, ok = AsType[*root](nil)
+
+	assert.False(t, ok)
+}
+
+func TestFindAll(t *testing.T) {
+	t.Parallel()
+
+	err1 := New("1")
+	err2 := New("2")
+	err2a := Wrap(err2, "2a")
+	external := errors.New("external")
+
+	joinedErr := Join(err1, Join(err2a, external))
+
+	roots := FindAll[*root](fmt.Errorf("outer: %w", joinedErr))
+
+	require.Len(t, roots, 2)
+	assert.Same(t, err1, roots[0])
+	assert.Same(t, err2, roots[1])
+
+	assert.Len(t, FindAll[Error](joinedErr), 5) // both joined errors, err1, err2a and err2
+	assert.Len(t, FindAll[*joined](joinedErr), 2)
+	assert.Empty(t, FindAll[*wrapped](err1))
+	assert.Nil(t, FindAll[*root](nil))
+}
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	err := New("root", WithField("user_id", 42), WithField("shadowed", "inner"))
+	err = Wrap(err, "wrapper", WithField("request_id", "abc"), WithField("shadowed", "outer"))
+	err = Join(errors.New("external"), err)
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+
+		userID, ok := Field[int](err, "user_id")
+
+		require.True(t, ok)
+		assert.Equal(t, 42, userID)
+
+		requestID, ok := Field[string](err, "request_id")
+
+		require.True(t, ok)
+		assert.Equal(t, "abc", requestID)
+	})
+
+	t.Run("outermost first", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "outer", MustField[string](err, "shadowed"))
+	})
+
+	t.Run("missing or mistyped", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := Field[int](err, "missing")
+
+		assert.False(t, ok)
+
+		_, ok = Field[string](err, "user_id")
+
+		assert.False(t, ok)
+
+		assert.PanicsWithValue(t, `errors: field "user_id" of type string not found`, func() {
+			MustField[string](err, "user_id")
+		})
+
+		assert.PanicsWithValue(t, `errors: field "user_id" of type error not found`, func() {
+			MustField[error](err, "user_id")
+		})
+	})
+}
+
 func TestJoin(t *testing.T) {
 	t.Parallel()
 
@@ -474,6 +763,87 @@ func TestJoin(t *testing.T) {
 
 		assert.Equal(t, []error{err1, err2}, unwrapped)
 	})
+
+	t.Run("join with type and fields", func(t *testing.T) {
+		t.Parallel()
+
+		err1 := New("error1", WithType("TYPE1"))
+
+		err := JoinWith([]error{err1, New("error2")}, WithType("BATCH"), WithField("batch_id", 7))
+
+		var e Error
+
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, Type("BATCH"), e.Type())
+		assert.Equal(t, map[string]any{"batch_id": 7}, e.Fields())
+		assert.ErrorIs(t, err, TypeMatcher("BATCH"))
+		assert.ErrorIs(t, err, TypeMatcher("TYPE1"))
+		assert.ErrorIs(t, err, err1)
+		assert.Contains(t, err.(*joined).stackFrames()[0].Name, "TestJoin")
+
+		single := JoinWith([]error{err1}, WithField("batch_id", 7))
+
+		require.IsType(t, &joined{}, single)
+		assert.Equal(t, []error{err1}, single.(*joined).Unwrap())
+		assert.Equal(t, err1, JoinWith([]error{nil, err1}))
+		assert.NoError(t, JoinWith(nil, WithType("BATCH")))
+	})
+
+	t.Run("join flatten", func(t *testing.T) {
+		t.Parallel()
+
+		err1 := New("error1")
+		err2 := New("error2")
+		err3 := New("error3")
+		typed := JoinWith([]error{err2, err3}, WithType("TYPED"))
+
+		err := JoinWith([]error{Join(err1, Join(err2, err3)), nil, typed}, WithFlatten())
+
+		assert.Equal(t, []error{err1, err2, err3, typed}, err.(*joined).Unwrap())
+	})
+
+	t.Run("join deduplicate", func(t *testing.T) {
+		t.Parallel()
+
+		var errs []error
+
+		for range 3 {
+			errs = append(errs, New("timeout", WithType("TIMEOUT")))
+		}
+
+		other := New("timeout", WithType("TIMEOUT"))
+		untyped := New("timeout")
+
+		err := JoinWith(append(errs, other, untyped, errs[0]), WithDeduplicate())
+
+		assert.Equal(t, []error{errs[0], other, untyped}, err.(*joined).Unwrap())
+		assert.Equal(t, errs[0], JoinWith(errs, WithDeduplicate()))
+	})
+
+	t.Run("join flatten and deduplicate", func(t *testing.T) {
+		t.Parallel()
+
+		err1 := New("error1")
+		err2 := New("error2")
+
+		err := JoinWith([]error{Join(err1, err2), Join(err2, err1)}, WithFlatten(), WithDeduplicate())
+
+		assert.Equal(t, []error{err1, err2}, err.(*joined).Unwrap())
+	})
+
+	t.Run("join format type and fields", func(t *testing.T) {
+		t.Parallel()
+
+		err := JoinWith([]error{New("error1"), New("error2")}, WithType("BATCH"), WithField("batch_id", 7))
+
+		assert.Equal(t, "[BATCH] Multiple errors (2):\n\nFields:\n  batch_id: 7\n\n1. error1\n\n2. error2", ToString(err))
+
+		formatted := ToJSON(err)
+
+		assert.Equal(t, "joined", formatted["type"])
+		assert.Equal(t, "BATCH", formatted["join_type"])
+		assert.Equal(t, map[string]any{"batch_id": 7}, formatted["fields"])
+	})
 }
 
 func TestCause(t *testing.T) {
@@ -524,3 +894,221 @@ func TestCause(t *testing.T) {
 		assert.Equal(t, joined, cause) // Joined error is the root cause
 	})
 }
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	rootErr := New("root", WithType("ROOT_TYPE"), WithField("key", "value"))
+	wrappedErr := Wrap(rootErr, "wrapper")
+	joinedErr := Join(New("error1"), New("error2"))
+
+	t.Run("message verbs", func(t *testing.T) {
+		t.Parallel()
+
+		for _, err := range []error{rootErr, wrappedErr, joinedErr} {
+			assert.Equal(t, err.Error(), fmt.Sprintf("%s", err))
+			assert.Equal(t, err.Error(), fmt.Sprintf("%v", err))
+			assert.Equal(t, fmt.Sprintf("%q", err.Error()), fmt.Sprintf("%q", err))
+		}
+	})
+
+	t.Run("detailed verb", func(t *testing.T) {
+		t.Parallel()
+
+		for _, err := range []error{rootErr, wrappedErr, joinedErr} {
+			assert.Equal(t, ToString(err, FormatWithTrace()), fmt.Sprintf("%+v", err))
+		}
+
+		detailed := fmt.Sprintf("%+v", wrappedErr)
+
+		assert.Contains(t, detailed, "[ROOT_TYPE] root")
+		assert.Contains(t, detailed, "key: value")
+		assert.Contains(t, detailed, "root Trace:")
+		assert.Contains(t, detailed, "wrap Trace:")
+	})
+
+	t.Run("unsupported verb", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "%!d(*errors.root=root)", fmt.Sprintf("%d", rootErr))
+	})
+}
+
+func TestFormatFrames(t *testing.T) {
+	t.Parallel()
+
+	err := Wrap(New("root"), "wrapper")
+
+	t.Run("exclude packages", func(t *testing.T) {
+		t.Parallel()
+
+		unfiltered := ToJSON(err, FormatWithTrace())
+		filtered := ToJSON(err, FormatWithTrace(), FormatExcludePackages("testing"))
+
+		assert.Contains(t, ToString(err, FormatWithTrace()), "testing.tRunner")
+		assert.NotContains(t, ToString(err, FormatWithTrace(), FormatExcludePackages("testing")), "testing.tRunner")
+		assert.Len(t, filtered["root"].(map[string]any)["stack"], len(unfiltered["root"].(map[string]any)["stack"].([]map[string]any))-1)
+	})
+
+	t.Run("include packages", func(t *testing.T) {
+		t.Parallel()
+
+		formated := ToJSON(err, FormatWithTrace(), FormatIncludePackages("github.com/hueristiq/hq-go-errors"))
+
+		for _, frame := range formated["root"].(map[string]any)["stack"].([]map[string]any) {
+			assert.Contains(t, frame["function"], "hq-go-errors.TestFormatFrames")
+		}
+
+		assert.NotContains(t, ToString(err, FormatWithTrace(), FormatIncludePackages("github.com/hueristiq/hq-go")), "TestFormatFrames")
+	})
+
+	t.Run("exclude frames", func(t *testing.T) {
+		t.Parallel()
+
+		formated := ToString(err, FormatWithTrace(), FormatExcludeFrames(regexp.MustCompile(`TestFormatFrames`)))
+
+		assert.NotContains(t, formated, "Trace:\n  hq-go-errors.TestFormatFrames")
+		assert.NotContains(t, formated, "wrap Trace:")
+		assert.Contains(t, formated, "root Trace:\n  testing.tRunner")
+	})
+
+	t.Run("include frames", func(t *testing.T) {
+		t.Parallel()
+
+		formated := ToString(err, FormatWithTrace(), FormatIncludeFrames(regexp.MustCompile(`^testing\.`)))
+
+		assert.Equal(t, "wrapper\n\nroot\n\nroot Trace:\n  testing.tRunner (", formated[:len("wrapper\n\nroot\n\nroot Trace:\n  testing.tRunner (")])
+	})
+
+	t.Run("trim paths", func(t *testing.T) {
+		t.Parallel()
+
+		formated := ToString(err, FormatWithTrace(), FormatTrimModuleRoot(), FormatTrimGoPaths())
+
+		assert.Contains(t, formated, "(errors_test.go:")
+		assert.Contains(t, formated, "(testing/testing.go:")
+	})
+
+	t.Run("full function names", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Contains(t, ToString(err, FormatWithTrace(), FormatWithFullFunctionNames()), "github.com/hueristiq/hq-go-errors.TestFormatFrames")
+
+		decoded := FromJSON(ToJSON(err, FormatWithTrace()))
+
+		assert.Equal(t, ToString(decoded, FormatWithTrace()), ToString(decoded, FormatWithTrace(), FormatWithFullFunctionNames()))
+	})
+}
+
+func TestTrimPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		file     string
+		prefixes []string
+		expected string
+	}{
+		{name: "no prefixes", file: "/src/app/main.go", prefixes: nil, expected: "/src/app/main.go"},
+		{name: "matching prefix", file: "/src/app/main.go", prefixes: []string{"/src"}, expected: "app/main.go"},
+		{name: "trailing slash", file: "/src/app/main.go", prefixes: []string{"/src/"}, expected: "app/main.go"},
+		{name: "partial directory", file: "/src/application/main.go", prefixes: []string{"/src/app"}, expected: "/src/application/main.go"},
+		{name: "first match wins", file: "/src/app/main.go", prefixes: []string{"/src/app", "/src"}, expected: "main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, trimPath(tt.file, tt.prefixes))
+		})
+	}
+}
+
+func TestUnpackTree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("external wrapper", func(t *testing.T) {
+		t.Parallel()
+
+		rootErr := New("root", WithType("ROOT_TYPE"), WithField("key", "value"))
+		err := Wrap(fmt.Errorf("context: %w", Wrap(rootErr, "inner")), "outer")
+
+		unpacked := Unpack(err)
+
+		require.Len(t, unpacked.ErrChain, 3)
+		assert.Equal(t, "outer", unpacked.ErrChain[0].Message)
+		assert.Equal(t, "context", unpacked.ErrChain[1].Message)
+		assert.NotNil(t, unpacked.ErrChain[1].External)
+		assert.Equal(t, "inner", unpacked.ErrChain[2].Message)
+		assert.Equal(t, Type("ROOT_TYPE"), unpacked.ErrRoot.Type)
+		assert.Equal(t, map[string]any{"key": "value"}, unpacked.ErrRoot.Fields)
+		assert.NotEmpty(t, unpacked.ErrRoot.Stack)
+		assert.NoError(t, unpacked.ErrExternal)
+
+		assert.Equal(t, "outer\n\ncontext\n\ninner\n\n[ROOT_TYPE] root\n\nFields:\n  key: value", ToString(err))
+
+		formated := ToJSON(err)
+
+		assert.Equal(t, "*fmt.wrapError", formated["chain"].([]map[string]any)[1]["go_type"])
+		assert.Equal(t, "ROOT_TYPE", formated["root"].(map[string]any)["type"])
+	})
+
+	t.Run("external only", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("context: %w", errors.New("external"))
+
+		unpacked := Unpack(err)
+
+		assert.Empty(t, unpacked.ErrChain)
+		assert.Equal(t, err, unpacked.ErrExternal)
+		assert.Equal(t, "context: external", ToString(err))
+	})
+
+	t.Run("nested join", func(t *testing.T) {
+		t.Parallel()
+
+		inner := Join(New("error2"), errors.New("external"))
+		joinedErr := Join(New("error1"), inner)
+		err := Wrap(fmt.Errorf("context: %w", joinedErr), "outer")
+
+		unpacked := Unpack(err)
+
+		require.Len(t, unpacked.ErrChain, 2)
+		assert.Equal(t, "outer", unpacked.ErrChain[0].Message)
+		assert.Equal(t, "context", unpacked.ErrChain[1].Message)
+		assert.Same(t, joinedErr, unpacked.ErrJoin)
+		require.Len(t, unpacked.ErrJoinedTrees, 2)
+		assert.Equal(t, "error1", unpacked.ErrJoinedTrees[0].ErrRoot.Message)
+		assert.Same(t, inner, unpacked.ErrJoinedTrees[1].ErrJoin)
+		require.Len(t, unpacked.ErrJoinedTrees[1].ErrJoinedTrees, 2)
+		assert.Equal(t, "error2", unpacked.ErrJoinedTrees[1].ErrJoinedTrees[0].ErrRoot.Message)
+
+		assert.Equal(t, "outer\n\ncontext\n\nMultiple errors (2):\n\n1. error1\n\n2. Multiple errors (2):\n\n1. error2\n\n2. external", ToString(err))
+
+		formated := ToJSON(err)
+
+		joined := formated["joined"].(map[string]any)
+
+		assert.Equal(t, "joined", joined["type"])
+		assert.Equal(t, 2, joined["count"])
+		assert.Len(t, formated["chain"], 2)
+	})
+
+	t.Run("external join", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.Join(New("error1"), errors.New("external"))
+
+		unpacked := Unpack(err)
+
+		assert.Equal(t, err, unpacked.ErrJoin)
+		assert.Len(t, unpacked.ErrJoinedTrees, 2)
+
+		unpacked = Unpack(errors.Join(errors.New("external1"), errors.New("external2")))
+
+		assert.NoError(t, unpacked.ErrJoin)
+		assert.Error(t, unpacked.ErrExternal)
+	})
+}
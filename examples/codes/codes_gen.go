@@ -0,0 +1,137 @@
+// Code generated by hqerrgen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+var (
+	// ErrPayments is the type of Payments errors.
+	// A payment operation failed.
+	// It can be used with WithType, IsType and, through TypeMatcher, with Is.
+	ErrPayments = hqgoerrors.Type("payments")
+	// ErrOrderNotFound is the type of OrderNotFound errors.
+	// The order does not exist.
+	// It can be used with WithType, IsType and, through TypeMatcher, with Is.
+	ErrOrderNotFound = hqgoerrors.RegisterType("payments.order_not_found", ErrPayments)
+	// ErrPaymentDeclined is the type of PaymentDeclined errors.
+	// The payment provider declined the payment.
+	// It can be used with WithType, IsType and, through TypeMatcher, with Is.
+	ErrPaymentDeclined = hqgoerrors.RegisterType("payments.declined", ErrPayments)
+	// ErrProviderUnavailable is the type of ProviderUnavailable errors.
+	// The payment provider could not be reached.
+	// It can be used with WithType, IsType and, through TypeMatcher, with Is.
+	ErrProviderUnavailable = hqgoerrors.RegisterType("payments.provider_unavailable", ErrPayments)
+)
+
+// Statuses maps the types of this catalog to their HTTP status codes.
+var Statuses = map[hqgoerrors.Type]int{
+	ErrPayments:            500,
+	ErrOrderNotFound:       404,
+	ErrPaymentDeclined:     402,
+	ErrProviderUnavailable: 503,
+}
+
+// RetryableTypes maps the types of this catalog with an explicit retry rule to whether
+// their operations can be retried.
+var RetryableTypes = map[hqgoerrors.Type]bool{
+	ErrPaymentDeclined:     false,
+	ErrProviderUnavailable: true,
+}
+
+func init() {
+	for errType, retryable := range RetryableTypes {
+		hqgoerrors.RegisterRetryableType(errType, retryable)
+	}
+}
+
+// NewPayments creates a Payments error.
+// A payment operation failed.
+// The stack trace starts at the caller.
+func NewPayments(ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New("payment failed", append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrPayments),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// WrapPayments wraps cause with a Payments error.
+// A payment operation failed.
+// The stack trace starts at the caller.
+func WrapPayments(cause error, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.Wrap(cause, "payment failed", append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrPayments),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// NewOrderNotFound creates a OrderNotFound error.
+// The order does not exist.
+// The stack trace starts at the caller.
+func NewOrderNotFound(orderID string, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New(fmt.Sprintf("order %v not found", orderID), append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrOrderNotFound),
+		hqgoerrors.WithField("component", "orders"),
+		hqgoerrors.WithField("order_id", orderID),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// WrapOrderNotFound wraps cause with a OrderNotFound error.
+// The order does not exist.
+// The stack trace starts at the caller.
+func WrapOrderNotFound(cause error, orderID string, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.Wrap(cause, fmt.Sprintf("order %v not found", orderID), append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrOrderNotFound),
+		hqgoerrors.WithField("component", "orders"),
+		hqgoerrors.WithField("order_id", orderID),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// NewPaymentDeclined creates a PaymentDeclined error.
+// The payment provider declined the payment.
+// The stack trace starts at the caller.
+func NewPaymentDeclined(amount float64, provider string, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New(fmt.Sprintf("payment of %v declined by %v", amount, provider), append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrPaymentDeclined),
+		hqgoerrors.WithField("amount", amount),
+		hqgoerrors.WithField("provider", provider),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// WrapPaymentDeclined wraps cause with a PaymentDeclined error.
+// The payment provider declined the payment.
+// The stack trace starts at the caller.
+func WrapPaymentDeclined(cause error, amount float64, provider string, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.Wrap(cause, fmt.Sprintf("payment of %v declined by %v", amount, provider), append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrPaymentDeclined),
+		hqgoerrors.WithField("amount", amount),
+		hqgoerrors.WithField("provider", provider),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// NewProviderUnavailable creates a ProviderUnavailable error.
+// The payment provider could not be reached.
+// The stack trace starts at the caller.
+func NewProviderUnavailable(ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.New("payment provider unavailable", append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrProviderUnavailable),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
+
+// WrapProviderUnavailable wraps cause with a ProviderUnavailable error.
+// The payment provider could not be reached.
+// The stack trace starts at the caller.
+func WrapProviderUnavailable(cause error, ofs ...hqgoerrors.OptionFunc) error {
+	return hqgoerrors.Wrap(cause, "payment provider unavailable", append([]hqgoerrors.OptionFunc{
+		hqgoerrors.WithType(ErrProviderUnavailable),
+		hqgoerrors.WithCallerSkip(1),
+	}, ofs...)...)
+}
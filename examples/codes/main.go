@@ -0,0 +1,22 @@
+package main
+
+//go:generate go run github.com/hueristiq/hq-go-errors/cmd/hqerrgen -in codes.yaml -out codes_gen.go -doc CODES.md
+
+import (
+	"fmt"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+func main() {
+	err := NewOrderNotFound("o-42")
+
+	err = WrapPaymentDeclined(err, 49.95, "acme")
+
+	fmt.Println(err)
+	fmt.Println(hqgoerrors.Is(err, hqgoerrors.TypeMatcher(ErrOrderNotFound)), hqgoerrors.IsType(err, ErrPayments), Statuses[ErrPaymentDeclined])
+
+	fmt.Println(hqgoerrors.ToString(err, hqgoerrors.FormatWithTrace()))
+
+	fmt.Println(hqgoerrors.IsRetryable(NewProviderUnavailable()))
+}
@@ -0,0 +1,420 @@
+package errors
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FingerprintOptions holds configuration for Fingerprint and Grouper.
+//
+// Fields:
+//   - Frames (int): number of root stack frames, most recent first, included in the fingerprint (default: 5)
+//   - IgnoreLines (bool): leave line numbers out, so edits that move code keep the fingerprint (default: false)
+//   - FrameFilters ([]FrameFilter): filters a stack frame must pass to be included (default: none)
+type FingerprintOptions struct {
+	Frames       int
+	IgnoreLines  bool
+	FrameFilters []FrameFilter
+}
+
+// FingerprintOptionFunc is a function type for configuring FingerprintOptions.
+// Used with Fingerprint and NewGrouper to set custom options.
+type FingerprintOptionFunc func(options *FingerprintOptions)
+
+// defaultFingerprintFrames is the number of root stack frames included in a fingerprint by default.
+const defaultFingerprintFrames = 5
+
+// FingerprintFrames returns a FingerprintOptionFunc that sets the number of root stack
+// frames included in the fingerprint. Zero or less leaves the stack out.
+//
+// Parameters:
+//   - frames (int): the number of frames
+//
+// Returns:
+//   - f (FingerprintOptionFunc): the option function
+func FingerprintFrames(frames int) (f FingerprintOptionFunc) {
+	return func(options *FingerprintOptions) {
+		options.Frames = frames
+	}
+}
+
+// FingerprintIgnoreLines returns a FingerprintOptionFunc that leaves line numbers out of
+// the fingerprint, so it only depends on the functions and files of the frames.
+//
+// Returns:
+//   - f (FingerprintOptionFunc): the option function
+func FingerprintIgnoreLines() (f FingerprintOptionFunc) {
+	return func(options *FingerprintOptions) {
+		options.IgnoreLines = true
+	}
+}
+
+// FingerprintWithFrameFilter returns a FingerprintOptionFunc that only includes frames
+// accepted by filter, e.g. to skip shared helpers or middleware. Several filters can be
+// combined; a frame must pass all of them.
+//
+// Parameters:
+//   - filter (FrameFilter): the filter to add
+//
+// Returns:
+//   - f (FingerprintOptionFunc): the option function
+func FingerprintWithFrameFilter(filter FrameFilter) (f FingerprintOptionFunc) {
+	return func(options *FingerprintOptions) {
+		options.FrameFilters = append(options.FrameFilters, filter)
+	}
+}
+
+// newFingerprintOptions returns the fingerprint options with the defaults and ofs applied.
+//
+// Parameters:
+//   - ofs ([]FingerprintOptionFunc): configuration options
+//
+// Returns:
+//   - options (FingerprintOptions): the configured options
+func newFingerprintOptions(ofs []FingerprintOptionFunc) (options FingerprintOptions) {
+	options.Frames = defaultFingerprintFrames
+
+	for _, f := range ofs {
+		f(&options)
+	}
+
+	return
+}
+
+// Fingerprint returns a stable identity of the failure site of err, suitable for grouping
+// errors in dashboards. It hashes:
+//
+//   - the type of the root error, or the Go type name of an external error without root;
+//   - the root message, normalized so that numbers, UUIDs and hexadecimal IDs do not matter;
+//   - the top frames of the root stack trace, as filtered by the options;
+//   - the Go type name and normalized message of the external error the root wraps, if any.
+//
+// Wrapping messages and fields are left out, so the same failure reached from different
+// requests shares a fingerprint. For a joined error, the fingerprints of its errors are
+// combined in order.
+//
+// Parameters:
+//   - err (error): the error to fingerprint
+//   - ofs (...FingerprintOptionFunc): configuration options
+//
+// Returns:
+//   - fingerprint (string): the hexadecimal fingerprint, or empty if err is nil
+func Fingerprint(err error, ofs ...FingerprintOptionFunc) (fingerprint string) {
+	if err == nil {
+		return
+	}
+
+	options := newFingerprintOptions(ofs)
+
+	fingerprint = fingerprintOf(err, &options)
+
+	return
+}
+
+// fingerprintOf hashes the identifying parts of err.
+//
+// Parameters:
+//   - err (error): the error to fingerprint
+//   - options (*FingerprintOptions): the fingerprint configuration
+//
+// Returns:
+//   - fingerprint (string): the hexadecimal fingerprint
+func fingerprintOf(err error, options *FingerprintOptions) (fingerprint string) {
+	var b strings.Builder
+
+	writeFingerprint(&b, err, options)
+
+	sum := sha256.Sum256([]byte(b.String()))
+
+	fingerprint = hex.EncodeToString(sum[:16])
+
+	return
+}
+
+// writeFingerprint writes the identifying parts of err to b, one per line, recursing
+// into joined errors. Like Unpack, it walks the chain down to the root, but uses the
+// root trace as captured, without the wrap points, so that wrapping does not change
+// the fingerprint.
+//
+// Parameters:
+//   - b (*strings.Builder): where to write
+//   - err (error): the error to fingerprint
+//   - options (*FingerprintOptions): the fingerprint configuration
+func writeFingerprint(b *strings.Builder, err error, options *FingerprintOptions) {
+	for _, err := range Chain(err) {
+		switch e := err.(type) {
+		case *root:
+			if e.cause != nil && hasPackageError(e.cause) {
+				continue
+			}
+
+			var frames Stack
+
+			switch {
+			case e.frames != nil:
+				frames = e.frames
+			case e.trace != nil:
+				frames = e.trace.resolveToStackFrames()
+			}
+
+			b.WriteString("type:" + string(e.Type()) + "\n")
+			b.WriteString("message:" + NormalizeMessage(e.message) + "\n")
+
+			for _, frame := range fingerprintFrames(frames, options) {
+				b.WriteString("frame:" + frame + "\n")
+			}
+
+			if e.cause != nil {
+				writeExternalFingerprint(b, e.cause)
+			}
+
+			return
+		case *wrapped:
+		case interface{ Unwrap() []error }:
+			if _, ok := err.(*joined); !ok && !hasPackageError(err) {
+				writeExternalFingerprint(b, err)
+
+				return
+			}
+
+			for i, err := range e.Unwrap() {
+				if err != nil {
+					b.WriteString("joined:" + strconv.Itoa(i) + "\n")
+
+					writeFingerprint(b, err, options)
+				}
+			}
+
+			return
+		default:
+			if cause := Unwrap(err); cause == nil || !hasPackageError(cause) {
+				writeExternalFingerprint(b, err)
+
+				return
+			}
+		}
+	}
+}
+
+// writeExternalFingerprint writes the identifying parts of an external error ending a chain to b.
+//
+// Parameters:
+//   - b (*strings.Builder): where to write
+//   - err (error): the external error
+func writeExternalFingerprint(b *strings.Builder, err error) {
+	b.WriteString("type:" + goType(err) + "\n")
+	b.WriteString("message:" + NormalizeMessage(err.Error()) + "\n")
+}
+
+// fingerprintFrames returns the identities of the top frames of a stack that pass the filters.
+//
+// Parameters:
+//   - frames (Stack): the root stack, most recent call first
+//   - options (*FingerprintOptions): the fingerprint configuration
+//
+// Returns:
+//   - identities ([]string): one "name file[:line]" identity per kept frame, using the
+//     simplified function name that frames decoded from JSON also have
+func fingerprintFrames(frames Stack, options *FingerprintOptions) (identities []string) {
+	for _, frame := range frames {
+		if len(identities) >= options.Frames {
+			break
+		}
+
+		if !slices.ContainsFunc(options.FrameFilters, func(filter FrameFilter) bool { return !filter(frame) }) {
+			identity := frame.Name + " " + frame.File
+
+			if !options.IgnoreLines {
+				identity += ":" + strconv.Itoa(frame.Line)
+			}
+
+			identities = append(identities, identity)
+		}
+	}
+
+	return
+}
+
+// messageNormalizers replace the variable parts of messages, most specific first.
+// A normalizer with a match function only replaces the matches it accepts.
+var messageNormalizers = []struct {
+	pattern     *regexp.Regexp
+	match       func(s string) bool
+	replacement string
+}{
+	{pattern: regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), replacement: "<uuid>"},
+	{pattern: regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), replacement: "<hex>"},
+	{pattern: regexp.MustCompile(`(?i)\b[0-9a-f]{8,}\b`), match: isHexID, replacement: "<hex>"},
+	{pattern: regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|us|ms|s|m|h|[KMG]?B)\b`), replacement: "<n>$2"},
+	{pattern: regexp.MustCompile(`\b\d+(\.\d+)?\b`), replacement: "<n>"},
+}
+
+// isHexID reports whether a token of hexadecimal digits looks like an ID, i.e. mixes
+// decimal digits and letters, unlike plain numbers and words such as "deadline".
+//
+// Parameters:
+//   - s (string): the token to check
+//
+// Returns:
+//   - ok (bool): true if s holds both a decimal digit and a letter
+func isHexID(s string) (ok bool) {
+	ok = strings.ContainsAny(s, "0123456789") && strings.ContainsAny(s, "abcdefABCDEF")
+
+	return
+}
+
+// NormalizeMessage replaces the variable parts of an error message, such as numbers,
+// UUIDs and hexadecimal IDs, with placeholders, so messages that only differ by the
+// values they mention compare equal. It is used by Fingerprint. Only whole tokens are
+// replaced: numbers inside identifiers (e.g. "sha256") are kept, numbers with a
+// duration or size unit keep the unit (e.g. "1.5s" becomes "<n>s"), and hexadecimal
+// IDs must be at least 8 characters long.
+//
+// Parameters:
+//   - message (string): the message to normalize
+//
+// Returns:
+//   - normalized (string): the message with its variable parts replaced
+func NormalizeMessage(message string) (normalized string) {
+	normalized = message
+
+	for _, normalizer := range messageNormalizers {
+		if normalizer.match == nil {
+			normalized = normalizer.pattern.ReplaceAllString(normalized, normalizer.replacement)
+
+			continue
+		}
+
+		normalized = normalizer.pattern.ReplaceAllStringFunc(normalized, func(s string) string {
+			if normalizer.match(s) {
+				return normalizer.replacement
+			}
+
+			return s
+		})
+	}
+
+	return
+}
+
+// ErrorGroup is the aggregate of the errors sharing a fingerprint, as kept by a Grouper.
+//
+// Fields:
+//   - Fingerprint (string): the fingerprint shared by the errors
+//   - Count (int): the number of errors seen
+//   - FirstSeen (time.Time): when the first error was seen
+//   - LastSeen (time.Time): when the last error was seen
+//   - Sample (error): the first error seen
+type ErrorGroup struct {
+	Fingerprint string
+	Count       int
+	FirstSeen   time.Time
+	LastSeen    time.Time
+	Sample      error
+}
+
+// Grouper counts errors by fingerprint, e.g. to log only the first occurrence of a
+// failure and report counts periodically. It is safe for concurrent use.
+//
+// Fields:
+//   - mu (sync.Mutex): guards groups
+//   - groups (map[string]*ErrorGroup): the groups, by fingerprint
+//   - options (FingerprintOptions): the fingerprint configuration
+type Grouper struct {
+	mu      sync.Mutex
+	groups  map[string]*ErrorGroup
+	options FingerprintOptions
+}
+
+// NewGrouper creates a Grouper computing fingerprints with the given options.
+//
+// Parameters:
+//   - ofs (...FingerprintOptionFunc): configuration options, as for Fingerprint
+//
+// Returns:
+//   - grouper (*Grouper): the new grouper
+func NewGrouper(ofs ...FingerprintOptionFunc) (grouper *Grouper) {
+	grouper = &Grouper{
+		groups:  map[string]*ErrorGroup{},
+		options: newFingerprintOptions(ofs),
+	}
+
+	return
+}
+
+// Add records err in the group of its fingerprint. Nil errors are ignored.
+//
+// Parameters:
+//   - err (error): the error to record
+//
+// Returns:
+//   - group (ErrorGroup): a snapshot of the error's group after recording it
+//   - first (bool): true if err is the first error of its group
+func (g *Grouper) Add(err error) (group ErrorGroup, first bool) {
+	if err == nil {
+		return
+	}
+
+	fingerprint := fingerprintOf(err, &g.options)
+
+	now := time.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	existing, ok := g.groups[fingerprint]
+	if !ok {
+		existing = &ErrorGroup{
+			Fingerprint: fingerprint,
+			FirstSeen:   now,
+			Sample:      err,
+		}
+
+		g.groups[fingerprint] = existing
+	}
+
+	existing.Count++
+	existing.LastSeen = now
+
+	group = *existing
+	first = !ok
+
+	return
+}
+
+// Groups returns a snapshot of all groups, most frequent first, and by first seen
+// for equal counts.
+//
+// Returns:
+//   - groups ([]ErrorGroup): the groups
+func (g *Grouper) Groups() (groups []ErrorGroup) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	groups = make([]ErrorGroup, 0, len(g.groups))
+
+	for _, group := range g.groups {
+		groups = append(groups, *group)
+	}
+
+	slices.SortFunc(groups, func(a, b ErrorGroup) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), a.FirstSeen.Compare(b.FirstSeen), strings.Compare(a.Fingerprint, b.Fingerprint))
+	})
+
+	return
+}
+
+// Reset removes all groups, e.g. after they have been reported.
+func (g *Grouper) Reset() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	clear(g.groups)
+}
@@ -0,0 +1,243 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var closureFunction = regexp.MustCompile(`\.func\d+\.\d+$`)
+
+func newFingerprintTestError(id int) error {
+	return New(fmt.Sprintf("order %d not found", id), WithType("NOT_FOUND"), WithField("order_id", id))
+}
+
+func TestNormalizeMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{message: "order 42 not found", expected: "order <n> not found"},
+		{message: "took 1.5s", expected: "took <n>s"},
+		{message: "timeout after 30s", expected: "timeout after <n>s"},
+		{message: "slow query: 250ms, 12µs, 10MB", expected: "slow query: <n>ms, <n>µs, <n>MB"},
+		{message: "retry in 1.5 seconds", expected: "retry in <n> seconds"},
+		{message: "attempt 3/5 failed", expected: "attempt <n>/<n> failed"},
+		{message: "user 123e4567-e89b-12d3-a456-426614174000 missing", expected: "user <uuid> missing"},
+		{message: "bad pointer 0xc000123abc", expected: "bad pointer <hex>"},
+		{message: "commit 9f86d081884c7d65 not found", expected: "commit <hex> not found"},
+		{message: "connection refused", expected: "connection refused"},
+		{message: "sha256 ipv4 http2 unsupported", expected: "sha256 ipv4 http2 unsupported"},
+		{message: "face1 deadline exceeded", expected: "face1 deadline exceeded"},
+		{message: "block 12345678 not found", expected: "block <n> not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, NormalizeMessage(tt.message))
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, Fingerprint(nil))
+	})
+
+	t.Run("same site", func(t *testing.T) {
+		t.Parallel()
+
+		var errs []error
+
+		for i := range 2 {
+			errs = append(errs, newFingerprintTestError(i))
+		}
+
+		errs[1] = Wrap(errs[1], "handler", WithField("request_id", "r-1"))
+
+		assert.Len(t, Fingerprint(errs[0]), 32)
+		assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+	})
+
+	t.Run("different sites", func(t *testing.T) {
+		t.Parallel()
+
+		a := New("order 1 not found", WithType("NOT_FOUND"))
+		b := New("order 1 not found", WithType("NOT_FOUND"))
+
+		assert.NotEqual(t, Fingerprint(a), Fingerprint(b))
+		assert.Equal(t, Fingerprint(a, FingerprintFrames(0)), Fingerprint(b, FingerprintFrames(0)))
+	})
+
+	t.Run("ignore lines", func(t *testing.T) {
+		t.Parallel()
+
+		a := New("failed")
+		b := New("failed")
+
+		assert.NotEqual(t, Fingerprint(a, FingerprintFrames(1)), Fingerprint(b, FingerprintFrames(1)))
+		assert.Equal(t, Fingerprint(a, FingerprintFrames(1), FingerprintIgnoreLines()), Fingerprint(b, FingerprintFrames(1), FingerprintIgnoreLines()))
+	})
+
+	t.Run("frame filter", func(t *testing.T) {
+		t.Parallel()
+
+		a := newFingerprintTestError(1)
+		b := func() error { return newFingerprintTestError(1) }()
+
+		assert.NotEqual(t, Fingerprint(a, FingerprintIgnoreLines()), Fingerprint(b, FingerprintIgnoreLines()))
+
+		skipClosures := FingerprintWithFrameFilter(func(frame StackFrame) bool {
+			return !closureFunction.MatchString(frame.Function)
+		})
+
+		assert.Equal(t, Fingerprint(a, FingerprintIgnoreLines(), skipClosures), Fingerprint(b, FingerprintIgnoreLines(), skipClosures))
+	})
+
+	t.Run("type and message", func(t *testing.T) {
+		t.Parallel()
+
+		options := []FingerprintOptionFunc{FingerprintFrames(0)}
+
+		assert.NotEqual(t, Fingerprint(New("failed", WithType("A")), options...), Fingerprint(New("failed", WithType("B")), options...))
+		assert.NotEqual(t, Fingerprint(New("failed"), options...), Fingerprint(New("timed out"), options...))
+	})
+
+	t.Run("external", func(t *testing.T) {
+		t.Parallel()
+
+		var wrapped, external []error
+
+		for _, host := range []string{"10.0.0.1", "10.0.0.2"} {
+			cause := errors.New("dial tcp " + host + ":5432: connection refused")
+
+			wrapped = append(wrapped, Wrap(cause, "connecting"))
+			external = append(external, fmt.Errorf("retrying: %w", cause))
+		}
+
+		assert.Equal(t, Fingerprint(wrapped[0]), Fingerprint(wrapped[1]))
+		assert.Equal(t, Fingerprint(external[0]), Fingerprint(external[1]))
+		assert.NotEqual(t, Fingerprint(wrapped[0]), Fingerprint(Wrap(&temporaryError{}, "connecting")))
+		assert.NotEqual(t, Fingerprint(external[0]), Fingerprint(&temporaryError{}))
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		t.Parallel()
+
+		a := newFingerprintTestError(1)
+		b := errors.New("other")
+
+		assert.Equal(t, Fingerprint(Join(a, b)), Fingerprint(Join(a, b)))
+		assert.NotEqual(t, Fingerprint(Join(a, b)), Fingerprint(Join(b, a)))
+		assert.NotEqual(t, Fingerprint(Join(a, b)), Fingerprint(a))
+	})
+
+	t.Run("decoded", func(t *testing.T) {
+		t.Parallel()
+
+		err := newFingerprintTestError(1)
+
+		decoded := FromJSON(ToJSON(err, FormatWithTrace()))
+
+		assert.Equal(t, Fingerprint(err), Fingerprint(decoded))
+	})
+}
+
+func TestGrouper(t *testing.T) {
+	t.Parallel()
+
+	t.Run("groups", func(t *testing.T) {
+		t.Parallel()
+
+		grouper := NewGrouper()
+
+		var errs []error
+
+		for i := range 2 {
+			errs = append(errs, newFingerprintTestError(i))
+		}
+
+		first := errs[0]
+
+		group, isFirst := grouper.Add(first)
+
+		assert.True(t, isFirst)
+		assert.Equal(t, 1, group.Count)
+		assert.Equal(t, Fingerprint(first), group.Fingerprint)
+
+		group, isFirst = grouper.Add(errs[1])
+
+		assert.False(t, isFirst)
+		assert.Equal(t, 2, group.Count)
+		assert.Same(t, first, group.Sample)
+		assert.False(t, group.LastSeen.Before(group.FirstSeen))
+
+		other := errors.New("other")
+
+		grouper.Add(other)
+
+		group, isFirst = grouper.Add(nil)
+
+		assert.False(t, isFirst)
+		assert.Zero(t, group)
+
+		groups := grouper.Groups()
+
+		require.Len(t, groups, 2)
+		assert.Equal(t, 2, groups[0].Count)
+		assert.Equal(t, 1, groups[1].Count)
+		assert.Equal(t, other, groups[1].Sample)
+
+		grouper.Reset()
+
+		assert.Empty(t, grouper.Groups())
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		grouper := NewGrouper(FingerprintFrames(0))
+
+		grouper.Add(New("order 1 not found"))
+		grouper.Add(New("order 2 not found"))
+
+		groups := grouper.Groups()
+
+		require.Len(t, groups, 1)
+		assert.Equal(t, 2, groups[0].Count)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		grouper := NewGrouper()
+
+		var wg sync.WaitGroup
+
+		for i := range 50 {
+			wg.Go(func() {
+				grouper.Add(newFingerprintTestError(i))
+			})
+		}
+
+		wg.Wait()
+
+		groups := grouper.Groups()
+
+		require.Len(t, groups, 1)
+		assert.Equal(t, 50, groups[0].Count)
+	})
+}
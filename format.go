@@ -3,6 +3,12 @@ package errors
 import (
 	"encoding/json"
 	"fmt"
+	"go/build"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -10,16 +16,25 @@ import (
 // It breaks down complex errors into their constituent parts for easier formatting and analysis.
 // This struct is used internally by formatting functions to organize error information.
 //
+// An UnpackedError is a tree: the chain of wraps ends either in a root (and possibly an
+// external cause), or in a joined error whose errors are unpacked into child trees.
+//
 // Fields:
-//   - ErrExternal (error): any external (non-package) error found in the chain
+//   - ErrExternal (error): the external (non-package) error ending the chain, if any
 //   - ErrRoot (ErrPart): the root error part, if present
-//   - ErrChain ([]ErrPart): the chain of wrapped error parts
-//   - ErrJoined ([]error): list of joined errors, if the error is a joined type
+//   - ErrChain ([]ErrPart): the chain of wrapped error parts, outermost first, including
+//     external wrappers with package errors below them
+//   - ErrJoin (error): the joined error ending the chain, if any (a joined error created by
+//     Join, or an external error implementing Unwrap() []error with package errors below it)
+//   - ErrJoined ([]error): the errors of ErrJoin
+//   - ErrJoinedTrees ([]UnpackedError): the unpacked errors of ErrJoin, one tree per non-nil error
 type UnpackedError struct {
-	ErrExternal error
-	ErrRoot     ErrPart
-	ErrChain    []ErrPart
-	ErrJoined   []error
+	ErrExternal    error
+	ErrRoot        ErrPart
+	ErrChain       []ErrPart
+	ErrJoin        error
+	ErrJoined      []error
+	ErrJoinedTrees []UnpackedError
 }
 
 // ErrPart represents a single component of an error, either root or wrapped.
@@ -30,11 +45,16 @@ type UnpackedError struct {
 //   - Type (Type): the classification type of this error part
 //   - Fields (map[string]any): structured key-value fields associated with this part
 //   - Stack (Stack): the stack trace frames for this error part
+//   - External (error): the external error of a chain part wrapping package errors
+//     (e.g. fmt.Errorf with %w), or nil for package errors
+//   - Formatted (bool): true if Message already embeds the cause's message (see Errorf)
 type ErrPart struct {
-	Message string
-	Type    Type
-	Fields  map[string]any
-	Stack   Stack
+	Message   string
+	Type      Type
+	Fields    map[string]any
+	Stack     Stack
+	External  error
+	Formatted bool
 }
 
 // Formatter is responsible for converting errors into human-readable string or JSON formats.
@@ -47,7 +67,8 @@ type Formatter struct {
 }
 
 // String formats the error as a multi-line string.
-// It handles both chain and joined errors differently.
+// It unpacks the error, redacted first in redacted mode, and renders the resulting tree,
+// joined errors included.
 //
 // Parameters:
 //   - err (error): the error to format
@@ -59,20 +80,20 @@ func (f *Formatter) String(err error) (formated string) {
 		return
 	}
 
-	switch e := err.(type) {
-	case *joined:
-		formated = f.formatJoinedString(e)
+	if f.options.Redact {
+		err = redact(err, f.options.SensitiveFields)
+	}
 
-		return
-	default:
-		formated = f.formatChainString(err)
+	unpacked := Unpack(err)
 
-		return
-	}
+	formated = f.formatChainString(&unpacked)
+
+	return
 }
 
 // JSON formats the error as a map suitable for JSON encoding.
-// It handles both chain and joined errors differently.
+// It unpacks the error, redacted first in redacted mode, and renders the resulting tree,
+// joined errors included.
 //
 // Parameters:
 //   - err (error): the error to format
@@ -84,36 +105,51 @@ func (f *Formatter) JSON(err error) (formated map[string]any) {
 		return
 	}
 
-	switch e := err.(type) {
-	case *joined:
-		formated = f.formatJoinedJSON(e)
+	if f.options.Redact {
+		err = redact(err, f.options.SensitiveFields)
+	}
 
-		return
-	default:
-		formated = f.formatChainJSON(err)
+	formated = f.unpackJSON(err)
 
-		return
-	}
+	return
 }
 
-// formatChainString formats a chain error (root + wraps) into a string.
-// It unpacks the error and assembles parts based on options (e.g., order, external inclusion).
+// unpackJSON unpacks the error and renders it as JSON does, without redacting it;
+// callers redact first when needed.
 //
 // Parameters:
-//   - err (error): the chain error to format
+//   - err (error): the error to format, non-nil
 //
 // Returns:
-//   - (string): the formatted string
-func (f *Formatter) formatChainString(err error) string {
+//   - formated (map[string]any): the formatted map
+func (f *Formatter) unpackJSON(err error) (formated map[string]any) {
 	unpacked := Unpack(err)
 
+	formated = f.formatChainJSON(&unpacked)
+
+	return
+}
+
+// formatChainString formats an unpacked error (wraps + root or joined errors) into a string.
+// It assembles parts based on options (e.g., order, external inclusion).
+//
+// Parameters:
+//   - unpacked (*UnpackedError): the unpacked error to format
+//
+// Returns:
+//   - (string): the formatted string
+func (f *Formatter) formatChainString(unpacked *UnpackedError) string {
 	var parts []string
 
 	if f.options.IsInnerFirst {
-		if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(&unpacked)) {
+		if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(unpacked)) {
 			parts = append(parts, f.formatExternalString(unpacked.ErrExternal))
 		}
 
+		if unpacked.ErrJoin != nil {
+			parts = append(parts, f.formatJoinedString(unpacked))
+		}
+
 		if f.hasRootContent(&unpacked.ErrRoot) {
 			parts = append(parts, f.formatPartString(&unpacked.ErrRoot, "root"))
 		}
@@ -130,7 +166,11 @@ func (f *Formatter) formatChainString(err error) string {
 			parts = append(parts, f.formatPartString(&unpacked.ErrRoot, "root"))
 		}
 
-		if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(&unpacked)) {
+		if unpacked.ErrJoin != nil {
+			parts = append(parts, f.formatJoinedString(unpacked))
+		}
+
+		if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(unpacked)) {
 			parts = append(parts, f.formatExternalString(unpacked.ErrExternal))
 		}
 	}
@@ -160,17 +200,15 @@ func (f *Formatter) formatPartString(part *ErrPart, kind string) string {
 
 	buf.WriteString(part.Message)
 
-	if len(part.Fields) > 0 {
+	if fields := f.fields(part.Fields); len(fields) > 0 {
 		buf.WriteString("\n\nFields:")
 
-		for k, v := range part.Fields {
+		for k, v := range fields {
 			buf.WriteString(fmt.Sprintf("\n%s%s:%s%v", f.options.Indentation, k, f.options.Spacing, v))
 		}
 	}
 
-	if f.options.WithTrace && len(part.Stack) > 0 {
-		frames := part.Stack
-
+	if frames := f.stack(part.Stack); len(frames) > 0 {
 		buf.WriteString(fmt.Sprintf("\n\n%s Trace:", kind))
 
 		for _, frame := range frames {
@@ -197,63 +235,86 @@ func (f *Formatter) formatExternalString(err error) string {
 	return err.Error()
 }
 
-// formatJoinedString formats a joined error into a string.
-// It includes the count, optional join location, and formats each sub-error recursively.
+// formatJoinedString formats the joined error ending an unpacked chain into a string.
+// It includes the message if set, the joined error's own type and fields if set, the count,
+// optional join location, and formats each joined tree recursively.
 //
 // Parameters:
-//   - joinErr (*joined): the joined error to format
+//   - unpacked (*UnpackedError): the unpacked error holding the joined error
 //
 // Returns:
 //   - (string): the formatted string
-func (f *Formatter) formatJoinedString(joinErr *joined) string {
+func (f *Formatter) formatJoinedString(unpacked *UnpackedError) string {
 	var buf strings.Builder
 
-	buf.WriteString(fmt.Sprintf("Multiple errors (%d):", len(joinErr.errors)))
+	joinErr, _ := unpacked.ErrJoin.(*joined)
 
-	if f.options.WithTrace && joinErr.trace != nil {
-		frames := joinErr.trace.resolveToStackFrames()
+	if joinErr != nil && joinErr.message != "" {
+		buf.WriteString(joinErr.message + "\n\n")
+	}
 
-		if len(frames) > 0 {
-			buf.WriteString("\n\nJoin Location:")
+	if joinErr != nil {
+		if errType := joinErr.Type(); errType != "" {
+			buf.WriteString("[" + string(errType) + "]" + f.options.Spacing)
+		}
+	}
+
+	buf.WriteString(fmt.Sprintf("Multiple errors (%d):", len(unpacked.ErrJoinedTrees)))
 
-			if len(frames) > 0 {
-				frame := frames[0]
+	if joinErr != nil {
+		if fields := f.fields(joinErr.Fields()); len(fields) > 0 {
+			buf.WriteString("\n\nFields:")
 
-				buf.WriteString(fmt.Sprintf("\n%s%s%s(%s:%d)", f.options.Indentation, frame.Name, f.options.Spacing, frame.File, frame.Line))
+			for k, v := range fields {
+				buf.WriteString(fmt.Sprintf("\n%s%s:%s%v", f.options.Indentation, k, f.options.Spacing, v))
 			}
 		}
-	}
 
-	for i, err := range joinErr.errors {
-		if err == nil {
-			continue
+		frames := f.stack(joinErr.stackFrames())
+
+		if len(frames) > 0 {
+			frame := frames[0]
+
+			buf.WriteString("\n\nJoin Location:")
+			buf.WriteString(fmt.Sprintf("\n%s%s%s(%s:%d)", f.options.Indentation, frame.Name, f.options.Spacing, frame.File, frame.Line))
 		}
+	}
 
-		buf.WriteString(fmt.Sprintf("\n\n%d. %s", i+1, f.String(err)))
+	for i := range unpacked.ErrJoinedTrees {
+		buf.WriteString(fmt.Sprintf("\n\n%d. %s", i+1, f.formatChainString(&unpacked.ErrJoinedTrees[i])))
 	}
 
 	return buf.String()
 }
 
-// formatChainJSON formats a chain error into a JSON-compatible map.
-// It unpacks the error and structures it with optional reversal based on options.
+// formatChainJSON formats an unpacked error into a JSON-compatible map.
+// It structures it with optional reversal based on options. An unpacked error that is
+// only a joined error is formatted as that joined error; a joined error below wraps
+// is nested under the "joined" key.
 //
 // Parameters:
-//   - err (error): the chain error to format
+//   - unpacked (*UnpackedError): the unpacked error to format
 //
 // Returns:
 //   - (map[string]any): the formatted map
-func (f *Formatter) formatChainJSON(err error) map[string]any {
-	unpacked := Unpack(err)
+func (f *Formatter) formatChainJSON(unpacked *UnpackedError) map[string]any {
+	if unpacked.ErrJoin != nil && len(unpacked.ErrChain) == 0 && !f.hasRootContent(&unpacked.ErrRoot) {
+		return f.formatJoinedJSON(unpacked)
+	}
+
 	result := make(map[string]any)
 
-	if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(&unpacked)) {
+	if unpacked.ErrExternal != nil && (f.options.WithExternal || f.isOnlyExternal(unpacked)) {
 		result["external"] = map[string]any{
 			"message": unpacked.ErrExternal.Error(),
-			"go_type": fmt.Sprintf("%T", unpacked.ErrExternal),
+			"go_type": goType(unpacked.ErrExternal),
 		}
 	}
 
+	if unpacked.ErrJoin != nil {
+		result["joined"] = f.formatJoinedJSON(unpacked)
+	}
+
 	if f.hasRootContent(&unpacked.ErrRoot) {
 		result["root"] = f.formatPartJSON(&unpacked.ErrRoot)
 	}
@@ -296,14 +357,20 @@ func (f *Formatter) formatPartJSON(part *ErrPart) map[string]any {
 		result["type"] = string(part.Type)
 	}
 
-	if len(part.Fields) > 0 {
-		result["fields"] = part.Fields
+	if fields := f.fields(part.Fields); len(fields) > 0 {
+		result["fields"] = fields
 	}
 
-	if f.options.WithTrace && len(part.Stack) > 0 {
-		var frames []map[string]any
+	if part.External != nil {
+		result["go_type"] = goType(part.External)
+	}
 
-		stack := part.Stack
+	if part.Formatted {
+		result["formatted"] = true
+	}
+
+	if stack := f.stack(part.Stack); len(stack) > 0 {
+		var frames []map[string]any
 
 		for _, frame := range stack {
 			frameMap := map[string]any{
@@ -329,24 +396,35 @@ func (f *Formatter) formatPartJSON(part *ErrPart) map[string]any {
 	return result
 }
 
-// formatJoinedJSON formats a joined error into a JSON-compatible map.
-// It includes type, count, optional join stack, and recursively formats sub-errors.
+// formatJoinedJSON formats the joined error ending an unpacked chain into a JSON-compatible map.
+// It includes type, count, message if set, the joined error's own type ("join_type") and fields if set,
+// optional join stack, and recursively formats the joined trees.
 //
 // Parameters:
-//   - joinErr (*joined): the joined error to format
+//   - unpacked (*UnpackedError): the unpacked error holding the joined error
 //
 // Returns:
 //   - (map[string]any): the formatted map
-func (f *Formatter) formatJoinedJSON(joinErr *joined) map[string]any {
+func (f *Formatter) formatJoinedJSON(unpacked *UnpackedError) map[string]any {
 	result := map[string]any{
 		"type":  "joined",
-		"count": len(joinErr.errors),
+		"count": len(unpacked.ErrJoinedTrees),
 	}
 
-	if f.options.WithTrace && joinErr.trace != nil {
-		frames := joinErr.trace.resolveToStackFrames()
+	if joinErr, ok := unpacked.ErrJoin.(*joined); ok {
+		if joinErr.message != "" {
+			result["message"] = joinErr.message
+		}
 
-		if len(frames) > 0 {
+		if errType := joinErr.Type(); errType != "" {
+			result["join_type"] = string(errType)
+		}
+
+		if fields := f.fields(joinErr.Fields()); len(fields) > 0 {
+			result["fields"] = fields
+		}
+
+		if frames := f.stack(joinErr.stackFrames()); len(frames) > 0 {
 			var joinFrames []map[string]any
 
 			for _, frame := range frames {
@@ -363,10 +441,8 @@ func (f *Formatter) formatJoinedJSON(joinErr *joined) map[string]any {
 
 	var errors []any
 
-	for _, err := range joinErr.errors {
-		if err != nil {
-			errors = append(errors, f.JSON(err))
-		}
+	for i := range unpacked.ErrJoinedTrees {
+		errors = append(errors, f.formatChainJSON(&unpacked.ErrJoinedTrees[i]))
 	}
 
 	result["errors"] = errors
@@ -408,21 +484,36 @@ func (f *Formatter) isOnlyExternal(unpacked *UnpackedError) bool {
 //   - WithExternal (bool): include external errors (default: true)
 //   - Spacing (string): spacing between elements (default: " ")
 //   - Indentation (string): indentation for nested elements (default: "  ")
+//   - FrameFilters ([]FrameFilter): filters a stack frame must pass to be included (default: none)
+//   - TrimPaths ([]string): path prefixes trimmed from stack frame files (default: none)
+//   - FullFunctionNames (bool): show the full import path in function names (default: false)
+//   - Redact (bool): replace sensitive messages and field values with a placeholder, as Redact does (default: false)
+//   - SensitiveFields (*regexp.Regexp): field keys whose values are always redacted (default: DefaultSensitiveFields)
 type FormatterOptions struct {
-	IsInnerFirst bool
-	WithTrace    bool
-	InvertTrace  bool
-	WithExternal bool
-	Spacing      string
-	Indentation  string
+	IsInnerFirst      bool
+	WithTrace         bool
+	InvertTrace       bool
+	WithExternal      bool
+	Spacing           string
+	Indentation       string
+	FrameFilters      []FrameFilter
+	TrimPaths         []string
+	FullFunctionNames bool
+	Redact            bool
+	SensitiveFields   *regexp.Regexp
 }
 
+// FrameFilter reports whether a stack frame should be included in formatted traces.
+// Filters see frames as resolved, before path trimming and name expansion.
+type FrameFilter func(frame StackFrame) (keep bool)
+
 // FormatterOptionFunc is a function type for configuring FormatterOptions.
 // Used with NewFormatter to set custom options.
 type FormatterOptionFunc func(options *FormatterOptions)
 
 // NewFormatter creates a new Formatter with default or custom options.
-// Defaults: outer-first, no trace, no invert, include external, space " ", indent "  ".
+// Defaults: outer-first, no trace, no invert, include external, space " ", indent "  ",
+// no redaction except for fields matching DefaultSensitiveFields.
 //
 // Parameters:
 //   - ofs (...FormatterOptionFunc): variadic option functions
@@ -431,12 +522,13 @@ type FormatterOptionFunc func(options *FormatterOptions)
 //   - formatter (*Formatter): the new formatter instance
 func NewFormatter(ofs ...FormatterOptionFunc) (formatter *Formatter) {
 	options := &FormatterOptions{
-		IsInnerFirst: false,
-		WithTrace:    false,
-		InvertTrace:  false,
-		WithExternal: true,
-		Spacing:      " ",
-		Indentation:  "  ",
+		IsInnerFirst:    false,
+		WithTrace:       false,
+		InvertTrace:     false,
+		WithExternal:    true,
+		Spacing:         " ",
+		Indentation:     "  ",
+		SensitiveFields: DefaultSensitiveFields,
 	}
 
 	for _, f := range ofs {
@@ -457,14 +549,346 @@ func FormatWithTrace() (f FormatterOptionFunc) {
 	}
 }
 
+// FormatWithFrameFilter returns an option function adding a custom frame filter.
+// A frame is included only if it passes every filter.
+//
+// Parameters:
+//   - filter (FrameFilter): the filter to add
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatWithFrameFilter(filter FrameFilter) (f FormatterOptionFunc) {
+	return func(options *FormatterOptions) {
+		options.FrameFilters = append(options.FrameFilters, filter)
+	}
+}
+
+// FormatIncludePackages returns an option function keeping only the frames of functions
+// in one of the given packages or their subpackages (e.g. "github.com/org/app").
+//
+// Parameters:
+//   - packages (...string): the import paths to keep
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatIncludePackages(packages ...string) (f FormatterOptionFunc) {
+	return FormatWithFrameFilter(func(frame StackFrame) (keep bool) {
+		keep = inPackages(frame, packages)
+
+		return
+	})
+}
+
+// FormatExcludePackages returns an option function dropping the frames of functions
+// in one of the given packages or their subpackages (e.g. "testing", "net/http").
+//
+// Parameters:
+//   - packages (...string): the import paths to drop
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatExcludePackages(packages ...string) (f FormatterOptionFunc) {
+	return FormatWithFrameFilter(func(frame StackFrame) (keep bool) {
+		keep = !inPackages(frame, packages)
+
+		return
+	})
+}
+
+// FormatIncludeFrames returns an option function keeping only the frames whose
+// fully qualified function name matches pattern.
+//
+// Parameters:
+//   - pattern (*regexp.Regexp): the pattern frames must match
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatIncludeFrames(pattern *regexp.Regexp) (f FormatterOptionFunc) {
+	return FormatWithFrameFilter(func(frame StackFrame) (keep bool) {
+		keep = pattern.MatchString(functionName(frame))
+
+		return
+	})
+}
+
+// FormatExcludeFrames returns an option function dropping the frames whose
+// fully qualified function name matches pattern.
+//
+// Parameters:
+//   - pattern (*regexp.Regexp): the pattern of frames to drop
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatExcludeFrames(pattern *regexp.Regexp) (f FormatterOptionFunc) {
+	return FormatWithFrameFilter(func(frame StackFrame) (keep bool) {
+		keep = !pattern.MatchString(functionName(frame))
+
+		return
+	})
+}
+
+// FormatTrimPaths returns an option function trimming the given directory prefixes
+// (e.g. a module root) from stack frame files. The first matching prefix is trimmed.
+//
+// Parameters:
+//   - prefixes (...string): the directories to trim
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatTrimPaths(prefixes ...string) (f FormatterOptionFunc) {
+	return func(options *FormatterOptions) {
+		for _, prefix := range prefixes {
+			if prefix == "" {
+				continue
+			}
+
+			options.TrimPaths = append(options.TrimPaths, filepath.ToSlash(prefix))
+		}
+	}
+}
+
+// FormatTrimGoPaths returns an option function trimming GOROOT and GOPATH from stack frame files,
+// so standard library and module cache frames show as import paths
+// (e.g. "runtime/proc.go", "github.com/org/lib@v1.2.3/lib.go").
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatTrimGoPaths() (f FormatterOptionFunc) {
+	var prefixes []string
+
+	if build.Default.GOROOT != "" {
+		prefixes = append(prefixes, filepath.Join(build.Default.GOROOT, "src"))
+	}
+
+	for _, path := range filepath.SplitList(build.Default.GOPATH) {
+		prefixes = append(prefixes, filepath.Join(path, "pkg", "mod"), filepath.Join(path, "src"))
+	}
+
+	return FormatTrimPaths(prefixes...)
+}
+
+// FormatTrimModuleRoot returns an option function trimming the root of the current module,
+// the nearest directory holding a go.mod file above the working directory,
+// from stack frame files. It is a no-op if no module root is found.
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatTrimModuleRoot() (f FormatterOptionFunc) {
+	return FormatTrimPaths(moduleRoot())
+}
+
+// FormatWithFullFunctionNames returns an option function showing fully qualified function names,
+// including the import path (e.g. "github.com/org/app/pkg.Func" instead of "pkg.Func").
+func FormatWithFullFunctionNames() (f FormatterOptionFunc) {
+	return func(options *FormatterOptions) {
+		options.FullFunctionNames = true
+	}
+}
+
+// FormatRedacted returns a FormatterOptionFunc that replaces every message and field value
+// not marked safe with RedactedPlaceholder, keeping types, stack traces and structure (see Redact).
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatRedacted() (f FormatterOptionFunc) {
+	return func(options *FormatterOptions) {
+		options.Redact = true
+	}
+}
+
+// FormatSensitiveFields returns a FormatterOptionFunc that sets the pattern of field keys
+// whose values are always redacted, replacing DefaultSensitiveFields.
+// A nil pattern disables it, so fields are only redacted in redacted mode.
+//
+// Parameters:
+//   - pattern (*regexp.Regexp): the pattern of sensitive field keys
+//
+// Returns:
+//   - f (FormatterOptionFunc): the option function
+func FormatSensitiveFields(pattern *regexp.Regexp) (f FormatterOptionFunc) {
+	return func(options *FormatterOptions) {
+		options.SensitiveFields = pattern
+	}
+}
+
+// fields returns the fields to render, with the values of sensitive keys replaced.
+// The given fields are not modified.
+//
+// Parameters:
+//   - fields (map[string]any): the fields to prepare
+//
+// Returns:
+//   - prepared (map[string]any): fields, or a copy with the sensitive values redacted
+func (f *Formatter) fields(fields map[string]any) (prepared map[string]any) {
+	prepared = fields
+
+	copied := false
+
+	for k := range fields {
+		if !isSensitiveField(k, f.options.SensitiveFields) {
+			continue
+		}
+
+		if !copied {
+			prepared = maps.Clone(fields)
+			copied = true
+		}
+
+		prepared[k] = RedactedPlaceholder
+	}
+
+	return
+}
+
+// stack applies the frame filters, path trimming and function naming options to a stack.
+// The given stack is not modified.
+//
+// Parameters:
+//   - frames (Stack): the stack to prepare
+//
+// Returns:
+//   - prepared (Stack): the frames to format, or nil if traces are disabled
+func (f *Formatter) stack(frames Stack) (prepared Stack) {
+	if !f.options.WithTrace {
+		return
+	}
+
+	if len(f.options.FrameFilters) == 0 && len(f.options.TrimPaths) == 0 && !f.options.FullFunctionNames {
+		prepared = frames
+
+		return
+	}
+
+	prepared = make(Stack, 0, len(frames))
+
+frames:
+	for _, frame := range frames {
+		for _, filter := range f.options.FrameFilters {
+			if !filter(frame) {
+				continue frames
+			}
+		}
+
+		if f.options.FullFunctionNames {
+			frame.Name = functionName(frame)
+		}
+
+		frame.File = trimPath(frame.File, f.options.TrimPaths)
+
+		prepared = append(prepared, frame)
+	}
+
+	return
+}
+
+// functionName returns the fully qualified function name of a frame,
+// falling back to its simplified name for frames decoded from JSON.
+//
+// Parameters:
+//   - frame (StackFrame): the frame
+//
+// Returns:
+//   - name (string): the function name
+func functionName(frame StackFrame) (name string) {
+	name = frame.Function
+
+	if name == "" {
+		name = frame.Name
+	}
+
+	return
+}
+
+// inPackages reports whether a frame's function belongs to one of the given packages or their subpackages.
+//
+// Parameters:
+//   - frame (StackFrame): the frame
+//   - packages ([]string): the import paths
+//
+// Returns:
+//   - in (bool): true if the function is in one of the packages
+func inPackages(frame StackFrame, packages []string) (in bool) {
+	name := functionName(frame)
+
+	for _, pkg := range packages {
+		rest, ok := strings.CutPrefix(name, pkg)
+
+		if ok && (rest == "" || rest[0] == '.' || rest[0] == '/') {
+			in = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// trimPath trims the first matching directory prefix from a file path.
+//
+// Parameters:
+//   - file (string): the file path
+//   - prefixes ([]string): the directory prefixes, slash-separated
+//
+// Returns:
+//   - trimmed (string): the path relative to the matching prefix, or file if none matches
+func trimPath(file string, prefixes []string) (trimmed string) {
+	trimmed = file
+
+	for _, prefix := range prefixes {
+		rest, ok := strings.CutPrefix(file, strings.TrimSuffix(prefix, "/"))
+
+		if ok && strings.HasPrefix(rest, "/") {
+			trimmed = rest[1:]
+
+			return
+		}
+	}
+
+	return
+}
+
+// moduleRoot returns the nearest directory holding a go.mod file above the working directory.
+//
+// Returns:
+//   - root (string): the module root, or empty if none is found
+func moduleRoot() (root string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return
+	}
+
+	for {
+		if _, err = os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			root = dir
+
+			return
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return
+		}
+
+		dir = parent
+	}
+}
+
 // Unpack decomposes an error into its parts.
-// It handles joined, root, wrapped, and external errors.
+// It handles joined, root, wrapped, and external errors anywhere in the chain.
 //
 // The unpacking process:
-//  1. If joined, sets ErrJoined and returns.
-//  2. Traverses the chain using Unwrap.
-//  3. For root/wrapped, extracts to ErrRoot/ErrChain.
-//  4. For external, sets ErrExternal.
+//  1. Traverses the chain using Chain.
+//  2. For wrapped, extracts to ErrChain.
+//  3. For root, extracts to ErrRoot, or to ErrChain if package errors remain below it.
+//  4. For joined errors, sets ErrJoin and ErrJoined, and unpacks each joined error into ErrJoinedTrees.
+//  5. For external wrappers with package errors below them, adds a part holding the
+//     wrapper's own message to ErrChain and keeps going.
+//  6. For any other external error, sets ErrExternal.
+//
+// The root's stack is resolved from a copy of its trace merged with the traces of the
+// wrapped errors above it, so the wrap points show up in the root trace without the
+// root ever being modified.
 //
 // Parameters:
 //   - err (error): the error to unpack
@@ -472,48 +896,161 @@ func FormatWithTrace() (f FormatterOptionFunc) {
 // Returns:
 //   - uerr (UnpackedError): the unpacked structure
 func Unpack(err error) (uerr UnpackedError) {
-	if joinErr, ok := err.(*joined); ok {
-		uerr.ErrJoined = joinErr.errors
+	var wraps []*stack
 
-		return
-	}
-
-	for err != nil {
+	for _, err := range Chain(err) {
 		switch e := err.(type) {
 		case *root:
-			uerr.ErrRoot = ErrPart{
-				Type:    e.errType,
-				Message: e.message,
-				Fields:  e.fields,
+			part := ErrPart{
+				Type:      e.Type(),
+				Message:   e.message,
+				Fields:    e.Fields(),
+				Formatted: e.isFormatted,
 			}
 
-			if e.trace != nil {
-				uerr.ErrRoot.Stack = e.trace.resolveToStackFrames()
+			switch {
+			case e.frames != nil:
+				part.Stack = e.frames
+			case e.trace != nil:
+				part.Stack = e.trace.merge(wraps).resolveToStackFrames()
+			}
+
+			if e.cause != nil && hasPackageError(e.cause) {
+				wraps = nil
+
+				uerr.ErrChain = append(uerr.ErrChain, part)
+
+				continue
 			}
+
+			uerr.ErrRoot = part
 		case *wrapped:
 			part := ErrPart{
-				Type:    e.errType,
-				Message: e.message,
-				Fields:  e.fields,
+				Type:      e.Type(),
+				Message:   e.message,
+				Fields:    e.Fields(),
+				Formatted: e.isFormatted,
 			}
 
-			if e.frame != nil {
+			switch {
+			case e.frames != nil:
+				part.Stack = e.frames
+			case e.frame != nil:
 				part.Stack = Stack{e.frame.resolveToStackFrame()}
 			}
 
+			if e.trace != nil {
+				wraps = append(wraps, e.trace)
+			}
+
 			uerr.ErrChain = append(uerr.ErrChain, part)
+		case interface{ Unwrap() []error }:
+			if _, ok := err.(*joined); !ok && !hasPackageError(err) {
+				uerr.ErrExternal = err
+
+				return
+			}
+
+			uerr.ErrJoin = err
+			uerr.ErrJoined = e.Unwrap()
+
+			for _, err := range uerr.ErrJoined {
+				if err != nil {
+					uerr.ErrJoinedTrees = append(uerr.ErrJoinedTrees, Unpack(err))
+				}
+			}
 		default:
-			uerr.ErrExternal = err
+			cause := Unwrap(err)
+
+			if cause == nil || !hasPackageError(cause) {
+				uerr.ErrExternal = err
+
+				return
+			}
+
+			uerr.ErrChain = append(uerr.ErrChain, ErrPart{
+				Message:  externalMessage(err, cause),
+				External: err,
+			})
+		}
+	}
+
+	return
+}
+
+// hasPackageError reports whether err or any error it wraps was created by this package.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - found (bool): true if a root, wrapped or joined error is found
+func hasPackageError(err error) (found bool) {
+	for err := range All(err) {
+		switch err.(type) {
+		case *root, *wrapped, *joined:
+			found = true
 
 			return
 		}
+	}
+
+	return
+}
 
-		err = Unwrap(err)
+// externalMessage returns the part of an external wrapper's message that it adds to its cause,
+// e.g. "context" for fmt.Errorf("context: %w", cause). It falls back to the full message if
+// the wrapper's message does not end with its cause's message.
+//
+// Parameters:
+//   - err (error): the external wrapper
+//   - cause (error): the error it wraps
+//
+// Returns:
+//   - message (string): the wrapper's own message
+func externalMessage(err, cause error) (message string) {
+	message = err.Error()
+
+	if trimmed, ok := strings.CutSuffix(message, ": "+cause.Error()); ok {
+		message = trimmed
 	}
 
 	return
 }
 
+// format writes err to state according to the given fmt verb.
+// It backs the fmt.Formatter implementations of the package's error types.
+//
+// The supported verbs are:
+//   - %s, %v: the plain error message (err.Error())
+//   - %q: the error message as a double-quoted Go string
+//   - %+v: the detailed representation, as produced by ToString(err, FormatWithTrace())
+//
+// Any other verb is reported using the fmt package's "%!verb(type=value)" convention.
+//
+// Parameters:
+//   - state (fmt.State): the formatter state provided by the fmt package
+//   - verb (rune): the formatting verb
+//   - err (error): the error to format
+func format(state fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		if state.Flag('+') {
+			_, _ = io.WriteString(state, ToString(err, FormatWithTrace()))
+
+			return
+		}
+
+		_, _ = io.WriteString(state, err.Error())
+	case 's':
+		_, _ = io.WriteString(state, err.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(state, "%q", err.Error())
+	default:
+		_, _ = fmt.Fprintf(state, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+}
+
 // ToString is a convenience function to format an error as a string.
 // It creates a formatter with options and calls String.
 //
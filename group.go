@@ -0,0 +1,278 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Group collects errors from concurrent work into a single joined error.
+// It is safe for concurrent use: errors can be added directly with Add or
+// produced by functions started with Go, in the manner of errgroup.
+//
+// The joined error returned by Err carries a stack trace captured where the
+// group was created, so the fan-out site shows up in formatted output.
+//
+// Fields:
+//   - mu (sync.Mutex): guards the collected errors
+//   - wg (sync.WaitGroup): tracks functions started with Go
+//   - errs ([]error): the collected errors, in the order they were added
+//   - seen (map[groupKey]struct{}): keys of collected errors, used for deduplication
+//   - dropped (int): number of errors not kept because of the limit
+//   - cancel (context.CancelCauseFunc): cancels the group's context, set by GroupWithContext
+//   - trace (*stack): captured call stack at the group creation site
+//   - isGlobal (bool): indicates if the group was created during package initialization
+//   - options (GroupOptions): the group configuration
+type Group struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	errs     []error
+	seen     map[groupKey]struct{}
+	dropped  int
+	cancel   context.CancelCauseFunc
+	trace    *stack
+	isGlobal bool
+	options  GroupOptions
+}
+
+// groupKey identifies an error for deduplication by its type and message.
+//
+// Fields:
+//   - errType (Type): the error type, or empty for untyped errors
+//   - message (string): the error message
+type groupKey struct {
+	errType Type
+	message string
+}
+
+// Add records err in the group. Nil errors are ignored.
+//
+// With deduplication enabled, an error with the same type and message as one
+// already collected is discarded. With a limit set, errors beyond the limit are
+// only counted and summarized by Err. The first recorded error cancels the
+// context returned by GroupWithContext.
+//
+// Parameters:
+//   - err (error): the error to record
+func (g *Group) Add(err error) {
+	if err == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.options.Deduplicate {
+		key := groupKey{errType: typeOf(err), message: err.Error()}
+
+		if _, ok := g.seen[key]; ok {
+			return
+		}
+
+		if g.seen == nil {
+			g.seen = make(map[groupKey]struct{})
+		}
+
+		g.seen[key] = struct{}{}
+	}
+
+	// only the first call has an effect, so the first error becomes the cause
+	if g.cancel != nil {
+		g.cancel(err)
+	}
+
+	if g.options.Limit > 0 && len(g.errs) >= g.options.Limit {
+		g.dropped++
+
+		return
+	}
+
+	g.errs = append(g.errs, err)
+}
+
+// Go runs fn in a new goroutine and records the error it returns.
+// A panic in fn is recovered and recorded as an error built by FromPanic.
+//
+// Parameters:
+//   - fn (func() error): the function to run
+func (g *Group) Go(fn func() error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		var err error
+
+		defer func() {
+			g.Add(err)
+		}()
+
+		defer Recover(&err)
+
+		err = fn()
+	}()
+}
+
+// Wait blocks until all functions started with Go have returned, then returns
+// the collected errors as Err does. It also cancels the context returned by
+// GroupWithContext, if any.
+//
+// Returns:
+//   - err (error): the joined collected errors, or nil if there are none
+func (g *Group) Wait() (err error) {
+	g.wg.Wait()
+
+	err = g.Err()
+
+	if g.cancel != nil {
+		g.cancel(err)
+	}
+
+	return
+}
+
+// Err returns the errors collected so far as a joined error whose stack trace
+// points at the group creation site. If errors were dropped because of the
+// limit, a final "and N more" error summarizes them. Like Join, it notifies the
+// hooks registered with OnCreate of the joined error, once per call.
+//
+// Err does not wait for functions started with Go; use Wait for that.
+//
+// Returns:
+//   - err (error): the joined collected errors, or nil if there are none
+func (g *Group) Err() (err error) {
+	errs := g.collected()
+
+	if len(errs) == 0 {
+		return
+	}
+
+	e := &joined{
+		errors:   errs,
+		trace:    g.trace,
+		isGlobal: g.isGlobal,
+	}
+
+	// hooks run outside the lock, so they may use the group
+	notify(EventJoin, e)
+
+	err = e
+
+	return
+}
+
+// collected returns a copy of the errors collected so far, followed by the "and N more"
+// error if errors were dropped.
+//
+// Returns:
+//   - errs ([]error): the collected errors, or nil if there are none
+func (g *Group) collected() (errs []error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return
+	}
+
+	errs = make([]error, len(g.errs), len(g.errs)+1)
+
+	copy(errs, g.errs)
+
+	if g.dropped > 0 {
+		errs = append(errs, &root{message: fmt.Sprintf("and %d more", g.dropped)})
+	}
+
+	return
+}
+
+// GroupOptions holds configuration for a Group.
+//
+// Fields:
+//   - Limit (int): maximum number of errors kept, or zero for no limit
+//   - Deduplicate (bool): whether errors with the same type and message are recorded once
+type GroupOptions struct {
+	Limit       int
+	Deduplicate bool
+}
+
+// GroupOptionFunc defines a function type for configuring GroupOptions.
+// Used with NewGroup and GroupWithContext to customize behavior.
+type GroupOptionFunc func(options *GroupOptions)
+
+// NewGroup creates a new Group, capturing a stack trace at the call site.
+//
+// Parameters:
+//   - ofs (...GroupOptionFunc): configuration options
+//
+// Returns:
+//   - group (*Group): the new group
+func NewGroup(ofs ...GroupOptionFunc) (group *Group) {
+	group = newGroup(ofs)
+
+	group.trace, group.isGlobal = captureOptions{}.callers(3, "") // callers(3) skips this method (NewGroup), callers, and runtime.Callers
+
+	return
+}
+
+// GroupWithContext creates a new Group and a context derived from ctx.
+// The derived context is canceled, with the error as its cause, when the first
+// error is recorded, or when Wait returns, whichever occurs first.
+//
+// Parameters:
+//   - ctx (context.Context): the parent context
+//   - ofs (...GroupOptionFunc): configuration options
+//
+// Returns:
+//   - group (*Group): the new group
+//   - derived (context.Context): the context canceled by the group
+func GroupWithContext(ctx context.Context, ofs ...GroupOptionFunc) (group *Group, derived context.Context) {
+	group = newGroup(ofs)
+
+	group.trace, group.isGlobal = captureOptions{}.callers(3, "") // callers(3) skips this method (GroupWithContext), callers, and runtime.Callers
+
+	derived, group.cancel = context.WithCancelCause(ctx)
+
+	return
+}
+
+// GroupWithLimit keeps at most limit errors in a Group.
+// Further errors are counted and summarized by a final "and N more" error.
+// A limit of zero or less keeps all errors.
+//
+// Parameters:
+//   - limit (int): the maximum number of errors kept
+//
+// Returns:
+//   - f (GroupOptionFunc): configuration function for NewGroup/GroupWithContext
+func GroupWithLimit(limit int) (f GroupOptionFunc) {
+	return func(options *GroupOptions) {
+		options.Limit = limit
+	}
+}
+
+// GroupDeduplicate records errors with the same type and message only once.
+//
+// Returns:
+//   - f (GroupOptionFunc): configuration function for NewGroup/GroupWithContext
+func GroupDeduplicate() (f GroupOptionFunc) {
+	return func(options *GroupOptions) {
+		options.Deduplicate = true
+	}
+}
+
+// newGroup creates a Group with the given options applied and no stack trace.
+//
+// Parameters:
+//   - ofs ([]GroupOptionFunc): configuration options
+//
+// Returns:
+//   - group (*Group): the new group
+func newGroup(ofs []GroupOptionFunc) (group *Group) {
+	group = &Group{}
+
+	for _, f := range ofs {
+		f(&group.options)
+	}
+
+	return
+}
@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Add(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no errors", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup()
+
+		g.Add(nil)
+
+		assert.NoError(t, g.Err())
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup()
+
+		var wg sync.WaitGroup
+
+		for i := range 50 {
+			wg.Go(func() {
+				g.Add(fmt.Errorf("error %d", i))
+			})
+		}
+
+		wg.Wait()
+
+		err := g.Err()
+
+		require.Error(t, err)
+		assert.Len(t, err.(*joined).Unwrap(), 50)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup(GroupWithLimit(2))
+
+		for i := range 5 {
+			g.Add(fmt.Errorf("error %d", i))
+		}
+
+		assert.Equal(t, "error 0\nerror 1\nand 3 more", g.Err().Error())
+	})
+
+	t.Run("deduplicate", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup(GroupDeduplicate())
+
+		g.Add(New("timeout", WithType("A")))
+		g.Add(New("timeout", WithType("A")))
+		g.Add(New("timeout", WithType("B")))
+		g.Add(New("refused", WithType("A")))
+
+		assert.Len(t, g.Err().(*joined).Unwrap(), 3)
+	})
+
+	t.Run("deduplicate before limit", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewGroup(GroupDeduplicate(), GroupWithLimit(1))
+
+		g.Add(New("timeout"))
+		g.Add(New("timeout"))
+
+		assert.Equal(t, "timeout", g.Err().Error())
+	})
+}
+
+func TestGroup_Err(t *testing.T) {
+	t.Parallel()
+
+	g := NewGroup()
+
+	g.Add(New("first"))
+
+	err := g.Err()
+
+	require.IsType(t, &joined{}, err)
+
+	frames := err.(*joined).stackFrames()
+
+	require.NotEmpty(t, frames)
+	assert.Contains(t, frames[0].Name, "TestGroup_Err")
+
+	g.Add(New("second"))
+
+	assert.Len(t, err.(*joined).Unwrap(), 1, "Err should return a snapshot")
+	assert.Len(t, g.Err().(*joined).Unwrap(), 2)
+}
+
+func TestGroup_Go(t *testing.T) {
+	t.Parallel()
+
+	failed := New("failed")
+
+	g := NewGroup()
+
+	g.Go(func() error { return nil })
+	g.Go(func() error { return failed })
+	g.Go(func() error {
+		panicWith("value")
+
+		return nil
+	})
+
+	err := g.Wait()
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, failed)
+	assert.True(t, IsType(err, TypePanic))
+	assert.Len(t, err.(*joined).Unwrap(), 2)
+}
+
+func TestGroupWithContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("canceled on first error", func(t *testing.T) {
+		t.Parallel()
+
+		first := New("first")
+
+		g, ctx := GroupWithContext(t.Context())
+
+		g.Go(func() error { return first })
+		g.Go(func() error {
+			<-ctx.Done()
+
+			return New("second")
+		})
+
+		err := g.Wait()
+
+		require.Error(t, err)
+		assert.ErrorIs(t, context.Cause(ctx), first)
+		assert.Len(t, err.(*joined).Unwrap(), 2)
+	})
+
+	t.Run("canceled on wait", func(t *testing.T) {
+		t.Parallel()
+
+		g, ctx := GroupWithContext(t.Context())
+
+		g.Go(func() error { return nil })
+
+		require.NoError(t, g.Wait())
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.ErrorIs(t, context.Cause(ctx), context.Canceled)
+	})
+}
@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// EventKind tells how an error observed by a hook was created.
+type EventKind string
+
+const (
+	// EventNew is the kind of root errors, created by New, Newf, Errorf without %w,
+	// NewSafe, NewCtx, FromPanic and Recover.
+	EventNew EventKind = "new"
+	// EventWrap is the kind of errors created by Wrap, Wrapf, Errorf with one %w,
+	// WrapSafe and WrapCtx.
+	EventWrap EventKind = "wrap"
+	// EventJoin is the kind of joined errors, created by Join, JoinWith, Errorf
+	// with several %w and Group.Err.
+	EventJoin EventKind = "join"
+)
+
+// Event describes an error just created, as passed to the hooks registered with OnCreate.
+//
+// Fields:
+//   - Kind (EventKind): how the error was created
+//   - Err (Error): the error; hooks may call SetType and SetField on it
+//   - Frame (StackFrame): where the error was created: the first frame of its stack
+//     trace, or the wrap point for wrapped errors (empty if stack capture is disabled
+//     for the error)
+//   - Type (Type): the error's type once its options and the hooks registered before
+//     were applied
+type Event struct {
+	Kind  EventKind
+	Err   Error
+	Frame StackFrame
+	Type  Type
+}
+
+// Hook is a function observing the errors created by the package (see OnCreate).
+type Hook func(event Event)
+
+// hookRegistration holds a registered hook, so that it can be removed by identity.
+//
+// Fields:
+//   - hook (Hook): the registered hook
+type hookRegistration struct {
+	hook Hook
+}
+
+// hooks holds the registered hooks. It is read on every error creation, so the list
+// is replaced as a whole under hooksMu on changes, and loaded atomically; it is nil
+// when no hook is registered, leaving a single atomic load on the creation path.
+//
+// Fields:
+//   - mu (sync.Mutex): serializes registrations and removals
+//   - registered (atomic.Pointer[[]*hookRegistration]): the current hooks, in registration order
+var hooks struct {
+	mu         sync.Mutex
+	registered atomic.Pointer[[]*hookRegistration]
+}
+
+// OnCreate registers a hook invoked synchronously, in the creating goroutine, for every
+// error created by the package, after its options are applied and its stack is captured.
+// Hooks run in registration order and can be used to observe errors (e.g. count them),
+// or to stamp them, with SetField and SetType on Event.Err:
+//
+//	remove := errors.OnCreate(func(event errors.Event) {
+//		event.Err.SetField("service", "billing")
+//	})
+//
+// Hooks must be cheap and must not panic. Errors decoded from JSON, and copies made by
+// Redact, are not created events. Registration is safe for concurrent use.
+//
+// Parameters:
+//   - hook (Hook): the hook to register
+//
+// Returns:
+//   - remove (func()): unregisters the hook; calling it more than once has no effect
+func OnCreate(hook Hook) (remove func()) {
+	registration := &hookRegistration{hook: hook}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+
+	var registered []*hookRegistration
+
+	if current := hooks.registered.Load(); current != nil {
+		registered = slices.Clone(*current)
+	}
+
+	registered = append(registered, registration)
+
+	hooks.registered.Store(&registered)
+
+	remove = sync.OnceFunc(func() {
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+
+		current := hooks.registered.Load()
+		if current == nil {
+			return
+		}
+
+		registered := slices.DeleteFunc(slices.Clone(*current), func(r *hookRegistration) bool {
+			return r == registration
+		})
+
+		if len(registered) == 0 {
+			hooks.registered.Store(nil)
+
+			return
+		}
+
+		hooks.registered.Store(&registered)
+	})
+
+	return
+}
+
+// SampleHook returns a Hook invoking hook for a random fraction of the events,
+// e.g. to keep an expensive hook affordable on hot paths.
+//
+// Parameters:
+//   - rate (float64): the fraction of events passed to hook, between 0 (none) and 1 (all)
+//   - hook (Hook): the hook to sample for
+//
+// Returns:
+//   - sampled (Hook): the sampling hook
+func SampleHook(rate float64, hook Hook) (sampled Hook) {
+	return func(event Event) {
+		if rate >= 1 || rand.Float64() < rate {
+			hook(event)
+		}
+	}
+}
+
+// notify invokes the registered hooks for an error just created.
+// It returns immediately if no hook is registered.
+//
+// Parameters:
+//   - kind (EventKind): how the error was created
+//   - err (Error): the error
+func notify(kind EventKind, err Error) {
+	registered := hooks.registered.Load()
+	if registered == nil {
+		return
+	}
+
+	event := Event{
+		Kind: kind,
+		Err:  err,
+	}
+
+	switch e := err.(type) {
+	case *root:
+		if e.trace != nil && len(*e.trace) > 0 {
+			event.Frame = frame((*e.trace)[0]).resolveToStackFrame()
+		}
+	case *wrapped:
+		if e.frame != nil {
+			event.Frame = e.frame.resolveToStackFrame()
+		}
+	case *joined:
+		if e.trace != nil && len(*e.trace) > 0 {
+			event.Frame = frame((*e.trace)[0]).resolveToStackFrame()
+		}
+	}
+
+	for _, registration := range *registered {
+		// refreshed for each hook, so hooks see the types stamped by the hooks before them
+		event.Type = err.Type()
+
+		registration.hook(event)
+	}
+}
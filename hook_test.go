@@ -0,0 +1,224 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // registers package-level hooks
+func TestOnCreate(t *testing.T) {
+	t.Run("events", func(t *testing.T) {
+		var events []Event
+
+		remove := OnCreate(func(event Event) {
+			events = append(events, event)
+		})
+
+		defer remove()
+
+		err := New("not found", WithType("NOT_FOUND"))
+		err = Wrap(err, "loading")
+		err = Errorf("handler: %w", err)
+		err = Join(err, errors.New("other"))
+
+		require.Len(t, events, 4)
+
+		assert.Equal(t, EventNew, events[0].Kind)
+		assert.Equal(t, Type("NOT_FOUND"), events[0].Type)
+		assert.Equal(t, "not found", events[0].Err.Error())
+		assert.Contains(t, events[0].Frame.Name, "TestOnCreate")
+		assert.Equal(t, "hook_test.go", filepath.Base(events[0].Frame.File))
+
+		assert.Equal(t, EventWrap, events[1].Kind)
+		assert.Contains(t, events[1].Frame.Name, "TestOnCreate")
+		assert.Equal(t, events[0].Frame.Line+1, events[1].Frame.Line)
+
+		assert.Equal(t, EventWrap, events[2].Kind)
+		assert.Equal(t, "handler: loading: not found", events[2].Err.Error())
+
+		assert.Equal(t, EventJoin, events[3].Kind)
+		assert.Same(t, err.(*joined), events[3].Err)
+	})
+
+	t.Run("other constructors", func(t *testing.T) {
+		var kinds []EventKind
+
+		remove := OnCreate(func(event Event) {
+			kinds = append(kinds, event.Kind)
+		})
+
+		defer remove()
+
+		_ = Newf("order %d", 42)
+		_ = NewSafe("safe")
+		_ = NewCtx(t.Context(), "ctx")
+		_ = FromPanic("boom")
+		_ = Errorf("failed")
+		_ = Errorf("%w and %w", errors.New("a"), errors.New("b"))
+		_ = Wrap(errors.New("external"), "wrapped")
+		_ = JoinWith([]error{New("a")}, WithType("BATCH"))
+
+		group := NewGroup()
+
+		group.Add(errors.New("a"))
+
+		_ = group.Err()
+
+		assert.Equal(t, []EventKind{
+			EventNew, EventNew, EventNew, EventNew, EventNew,
+			EventJoin,
+			EventWrap,
+			EventNew, EventJoin,
+			EventJoin,
+		}, kinds)
+	})
+
+	t.Run("stamping", func(t *testing.T) {
+		remove := OnCreate(func(event Event) {
+			if event.Kind == EventNew {
+				event.Err.SetField("service", "billing")
+			}
+
+			if event.Type == "" {
+				event.Err.SetType("UNKNOWN")
+			}
+		})
+
+		defer remove()
+
+		err := New("failed")
+
+		assert.Equal(t, map[string]any{"service": "billing"}, err.(*root).Fields())
+		assert.Equal(t, Type("UNKNOWN"), err.(*root).Type())
+		assert.Equal(t, Type("TYPED"), New("failed", WithType("TYPED")).(*root).Type())
+
+		value, ok := Field[string](Wrap(err, "wrapper"), "service")
+
+		assert.True(t, ok)
+		assert.Equal(t, "billing", value)
+	})
+
+	t.Run("stamped type seen by later hooks", func(t *testing.T) {
+		var types []Type
+
+		removeFirst := OnCreate(func(event Event) { event.Err.SetType("X") })
+		removeSecond := OnCreate(func(event Event) { types = append(types, event.Type) })
+
+		defer removeFirst()
+		defer removeSecond()
+
+		_ = New("failed", WithType("T"))
+
+		assert.Equal(t, []Type{"X"}, types)
+	})
+
+	t.Run("order and removal", func(t *testing.T) {
+		var calls []string
+
+		removeFirst := OnCreate(func(Event) { calls = append(calls, "first") })
+		removeSecond := OnCreate(func(Event) { calls = append(calls, "second") })
+
+		_ = New("failed")
+
+		removeFirst()
+		removeFirst()
+
+		_ = New("failed")
+
+		removeSecond()
+
+		_ = New("failed")
+
+		assert.Equal(t, []string{"first", "second", "second"}, calls)
+		assert.Nil(t, hooks.registered.Load())
+	})
+
+	t.Run("stack capture disabled", func(t *testing.T) {
+		var events []Event
+
+		remove := OnCreate(func(event Event) {
+			events = append(events, event)
+		})
+
+		defer remove()
+
+		_ = New("failed", WithoutStack())
+
+		require.Len(t, events, 1)
+		assert.Zero(t, events[0].Frame)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var count atomic.Int64
+
+		var wg sync.WaitGroup
+
+		for i := range 20 {
+			wg.Go(func() {
+				remove := OnCreate(func(Event) { count.Add(1) })
+
+				_ = New(fmt.Sprintf("error %d", i))
+
+				remove()
+			})
+		}
+
+		wg.Wait()
+
+		assert.GreaterOrEqual(t, count.Load(), int64(20))
+		assert.Nil(t, hooks.registered.Load())
+	})
+}
+
+func TestSampleHook(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+
+	never := SampleHook(0, func(Event) { calls++ })
+	always := SampleHook(1, func(Event) { calls++ })
+
+	for range 100 {
+		never(Event{})
+	}
+
+	assert.Zero(t, calls)
+
+	for range 100 {
+		always(Event{})
+	}
+
+	assert.Equal(t, 100, calls)
+
+	calls = 0
+
+	half := SampleHook(0.5, func(Event) { calls++ })
+
+	for range 1000 {
+		half(Event{})
+	}
+
+	assert.Greater(t, calls, 300)
+	assert.Less(t, calls, 700)
+}
+
+func BenchmarkNew_Hook(b *testing.B) {
+	remove := OnCreate(func(event Event) {
+		event.Err.SetField("service", "billing")
+	})
+
+	defer remove()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = New("error")
+	}
+}
@@ -0,0 +1,504 @@
+// Package httperrors converts errors to and from RFC 7807 "application/problem+json" documents.
+//
+// On the server side a Responder maps an error's Type to the problem's "type", "title"
+// and HTTP status, and exposes selected Fields as extension members. On the client side
+// Decode parses a problem document from an http.Response back into an error with the
+// same Type and fields.
+package httperrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+// ContentType is the media type of problem documents, as defined by RFC 7807.
+const ContentType = "application/problem+json"
+
+// blankType is the problem type used when an error has no Type, as defined by RFC 7807.
+const blankType = "about:blank"
+
+// traceMember is the extension member holding the error's formatted chain and stack traces in debug mode.
+const traceMember = "trace"
+
+// Problem represents an RFC 7807 problem details document.
+// Extension members are flattened into the top-level JSON object when marshaled.
+//
+// Fields:
+//   - Type (string): URI reference identifying the problem type
+//   - Title (string): short, human-readable summary of the problem type
+//   - Status (int): HTTP status code for this occurrence of the problem
+//   - Detail (string): human-readable explanation specific to this occurrence
+//   - Instance (string): URI reference identifying this occurrence
+//   - Extensions (map[string]any): additional members (e.g. the error's fields)
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON implements json.Marshaler, flattening Extensions into the document.
+// Standard members take precedence over extensions with the same name.
+//
+// Returns:
+//   - data ([]byte): the JSON encoding
+//   - err (error): any error from encoding
+func (p *Problem) MarshalJSON() (data []byte, err error) {
+	document := make(map[string]any, len(p.Extensions)+5)
+
+	maps.Copy(document, p.Extensions)
+
+	document["type"] = p.Type
+	document["title"] = p.Title
+
+	if p.Status != 0 {
+		document["status"] = p.Status
+	}
+
+	if p.Detail != "" {
+		document["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		document["instance"] = p.Instance
+	}
+
+	data, err = json.Marshal(document)
+
+	return
+}
+
+// UnmarshalJSON implements json.Unmarshaler, collecting non-standard members into Extensions.
+//
+// Parameters:
+//   - data ([]byte): the JSON encoding
+//
+// Returns:
+//   - err (error): any error from decoding
+func (p *Problem) UnmarshalJSON(data []byte) (err error) {
+	var document map[string]any
+
+	if err = json.Unmarshal(data, &document); err != nil {
+		return
+	}
+
+	*p = Problem{}
+
+	p.Type, _ = document["type"].(string)
+	p.Title, _ = document["title"].(string)
+	p.Detail, _ = document["detail"].(string)
+	p.Instance, _ = document["instance"].(string)
+
+	if status, ok := document["status"].(float64); ok {
+		p.Status = int(status)
+	}
+
+	for _, member := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(document, member)
+	}
+
+	if len(document) > 0 {
+		p.Extensions = document
+	}
+
+	return
+}
+
+// Options holds the configuration shared by Responder and Decode.
+//
+// Fields:
+//   - TypeBaseURI (string): prefix joined with an error's Type to build the problem "type" (default: "")
+//   - Statuses (map[hqgoerrors.Type]int): HTTP status per error Type
+//   - DefaultStatus (int): HTTP status for errors whose Type is not in Statuses (default: 500)
+//   - Fields ([]string): keys of the error fields exposed as extension members (default: none)
+//   - Debug (bool): include the error chain with stack traces as the "trace" member (default: false)
+type Options struct {
+	TypeBaseURI   string
+	Statuses      map[hqgoerrors.Type]int
+	DefaultStatus int
+	Fields        []string
+	Debug         bool
+}
+
+// OptionFunc is a function type for configuring Options.
+// Used with NewResponder and Decode to set custom options.
+type OptionFunc func(options *Options)
+
+// Responder writes errors as problem documents.
+//
+// Fields:
+//   - options (*Options): the configuration options for the mapping
+//   - formatter (*hqgoerrors.Formatter): the formatter used for the debug "trace" member
+type Responder struct {
+	options   *Options
+	formatter *hqgoerrors.Formatter
+}
+
+// Problem maps an error to a problem document.
+//
+// The mapping process:
+//  1. Unpacks the error and takes the outermost non-empty Type in the chain, or the
+//     Type of the joined error ending it.
+//  2. Builds "type" from TypeBaseURI and the Type, or "about:blank" if untyped.
+//  3. Looks up the status of the Type in Statuses, then of its parents registered with
+//     hqgoerrors.RegisterType, falling back to DefaultStatus.
+//  4. Sets "title" to the Type, or the status text if untyped, and "detail" to the error message.
+//  5. Copies the selected Fields, outer errors overriding inner ones, as extension members.
+//  6. In debug mode, adds the formatted chain with stack traces as the "trace" member.
+//
+// The detail and the extension members are taken from hqgoerrors.Redact(err), so only
+// messages and fields marked safe (see hqgoerrors.NewSafe and hqgoerrors.WithSafeField)
+// are exposed; the others are replaced by hqgoerrors.RedactedPlaceholder. The debug
+// trace is not redacted.
+//
+// Parameters:
+//   - err (error): the error to map
+//
+// Returns:
+//   - problem (*Problem): the problem document, or nil if err is nil
+func (r *Responder) Problem(err error) (problem *Problem) {
+	if err == nil {
+		return
+	}
+
+	redacted := hqgoerrors.Redact(err)
+
+	unpacked := hqgoerrors.Unpack(redacted)
+
+	errType := typeOf(&unpacked)
+
+	status := r.status(errType)
+
+	problem = &Problem{
+		Type:   blankType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: redacted.Error(),
+	}
+
+	if errType != "" {
+		problem.Type = r.options.TypeBaseURI + string(errType)
+		problem.Title = string(errType)
+	}
+
+	fields := fieldsOf(&unpacked)
+
+	for _, key := range r.options.Fields {
+		if value, ok := fields[key]; ok {
+			if problem.Extensions == nil {
+				problem.Extensions = map[string]any{}
+			}
+
+			problem.Extensions[key] = value
+		}
+	}
+
+	if r.options.Debug {
+		if problem.Extensions == nil {
+			problem.Extensions = map[string]any{}
+		}
+
+		problem.Extensions[traceMember] = r.formatter.JSON(err)
+	}
+
+	return
+}
+
+// status returns the HTTP status of an error Type: the status of the Type, or else of
+// its closest registered ancestor with a status.
+//
+// Parameters:
+//   - errType (hqgoerrors.Type): the error type, possibly empty
+//
+// Returns:
+//   - status (int): the mapped status, or DefaultStatus if no ancestor has one
+func (r *Responder) status(errType hqgoerrors.Type) (status int) {
+	for t := errType; t != ""; t = t.Parent() {
+		if s, ok := r.options.Statuses[t]; ok {
+			status = s
+
+			return
+		}
+	}
+
+	status = r.options.DefaultStatus
+
+	return
+}
+
+// Write writes err to w as a problem document with the mapped status code.
+// Nothing is written if err is nil.
+//
+// Parameters:
+//   - w (http.ResponseWriter): the response writer
+//   - err (error): the error to write
+//
+// Returns:
+//   - writeErr (error): any error from encoding or writing the document
+func (r *Responder) Write(w http.ResponseWriter, err error) (writeErr error) {
+	problem := r.Problem(err)
+	if problem == nil {
+		return
+	}
+
+	data, writeErr := json.Marshal(problem)
+	if writeErr != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(problem.Status)
+
+	_, writeErr = w.Write(data)
+
+	return
+}
+
+// NewResponder creates a new Responder with default or custom options.
+// Defaults: no type base URI, no status table, status 500, no fields, no debug trace.
+//
+// Parameters:
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - responder (*Responder): the new responder instance
+func NewResponder(ofs ...OptionFunc) (responder *Responder) {
+	responder = &Responder{
+		options:   newOptions(ofs...),
+		formatter: hqgoerrors.NewFormatter(hqgoerrors.FormatWithTrace()),
+	}
+
+	return
+}
+
+// Decode parses a problem document from an HTTP response back into an error.
+// It should be called with the same TypeBaseURI option as the server's Responder.
+//
+// If the document carries a debug "trace" member, the full error chain is rebuilt
+// with hqgoerrors.FromJSON. Otherwise a new error is created with the "detail"
+// (or "title") as message, the Type recovered from "type", and every extension
+// member as a field.
+//
+// The response body is read but not closed.
+//
+// Parameters:
+//   - res (*http.Response): the response to decode
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - decoded (error): the decoded error, or nil if the response is not a problem document
+//   - err (error): any error from reading or decoding the body
+func Decode(res *http.Response, ofs ...OptionFunc) (decoded, err error) {
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != ContentType {
+		return
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+
+	problem := &Problem{}
+
+	if err = json.Unmarshal(data, problem); err != nil {
+		err = fmt.Errorf("decoding problem document: %w", err)
+
+		return
+	}
+
+	if problem.Status == 0 {
+		problem.Status = res.StatusCode
+	}
+
+	decoded = FromProblem(problem, ofs...)
+
+	return
+}
+
+// FromProblem converts a problem document back into an error, as described for Decode.
+//
+// Parameters:
+//   - problem (*Problem): the problem document
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - err (error): the rebuilt error, or nil if problem is nil
+func FromProblem(problem *Problem, ofs ...OptionFunc) (err error) {
+	if problem == nil {
+		return
+	}
+
+	if trace, ok := problem.Extensions[traceMember].(map[string]any); ok {
+		if err = hqgoerrors.FromJSON(trace); err != nil {
+			return
+		}
+	}
+
+	options := newOptions(ofs...)
+
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+
+	var errOFs []hqgoerrors.OptionFunc
+
+	if problem.Type != "" && problem.Type != blankType {
+		errOFs = append(errOFs, hqgoerrors.WithType(hqgoerrors.Type(strings.TrimPrefix(problem.Type, options.TypeBaseURI))))
+	}
+
+	for key, value := range problem.Extensions {
+		errOFs = append(errOFs, hqgoerrors.WithField(key, value))
+	}
+
+	err = hqgoerrors.New(message, errOFs...)
+
+	return
+}
+
+// WithTypeBaseURI returns an option function that sets the prefix of problem types.
+//
+// Parameters:
+//   - uri (string): the prefix, e.g. "https://example.com/problems/"
+//
+// Returns:
+//   - f (OptionFunc): configuration function for NewResponder/Decode
+func WithTypeBaseURI(uri string) (f OptionFunc) {
+	return func(options *Options) {
+		options.TypeBaseURI = uri
+	}
+}
+
+// WithStatus returns an option function that maps an error Type to an HTTP status.
+//
+// Parameters:
+//   - errType (hqgoerrors.Type): the error type
+//   - status (int): the HTTP status code
+//
+// Returns:
+//   - f (OptionFunc): configuration function for NewResponder/Decode
+func WithStatus(errType hqgoerrors.Type, status int) (f OptionFunc) {
+	return func(options *Options) {
+		if options.Statuses == nil {
+			options.Statuses = map[hqgoerrors.Type]int{}
+		}
+
+		options.Statuses[errType] = status
+	}
+}
+
+// WithDefaultStatus returns an option function that sets the status of unmapped errors.
+//
+// Parameters:
+//   - status (int): the HTTP status code
+//
+// Returns:
+//   - f (OptionFunc): configuration function for NewResponder/Decode
+func WithDefaultStatus(status int) (f OptionFunc) {
+	return func(options *Options) {
+		options.DefaultStatus = status
+	}
+}
+
+// WithFields returns an option function that exposes the given error fields as extension members.
+//
+// Parameters:
+//   - keys (...string): the field keys to expose
+//
+// Returns:
+//   - f (OptionFunc): configuration function for NewResponder/Decode
+func WithFields(keys ...string) (f OptionFunc) {
+	return func(options *Options) {
+		options.Fields = append(options.Fields, keys...)
+	}
+}
+
+// WithDebug returns an option function that includes the error chain with stack traces.
+// It should only be enabled in development, as traces expose internal details.
+//
+// Parameters:
+//   - debug (bool): whether to include the "trace" member
+//
+// Returns:
+//   - f (OptionFunc): configuration function for NewResponder/Decode
+func WithDebug(debug bool) (f OptionFunc) {
+	return func(options *Options) {
+		options.Debug = debug
+	}
+}
+
+// newOptions creates Options with the defaults and applies the option functions.
+//
+// Parameters:
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - options (*Options): the configured options
+func newOptions(ofs ...OptionFunc) (options *Options) {
+	options = &Options{
+		DefaultStatus: http.StatusInternalServerError,
+	}
+
+	for _, f := range ofs {
+		f(options)
+	}
+
+	return
+}
+
+// typeOf returns the outermost non-empty Type of an unpacked error: the Type of a
+// wrapper, else of the root, else of the joined error ending the chain.
+//
+// Parameters:
+//   - unpacked (*hqgoerrors.UnpackedError): the unpacked error
+//
+// Returns:
+//   - errType (hqgoerrors.Type): the type, or empty if no part is typed
+func typeOf(unpacked *hqgoerrors.UnpackedError) (errType hqgoerrors.Type) {
+	for _, part := range unpacked.ErrChain {
+		if part.Type != "" {
+			errType = part.Type
+
+			return
+		}
+	}
+
+	errType = unpacked.ErrRoot.Type
+
+	if x, ok := unpacked.ErrJoin.(interface{ Type() hqgoerrors.Type }); errType == "" && ok {
+		errType = x.Type()
+	}
+
+	return
+}
+
+// fieldsOf merges the fields of an unpacked error, outer errors overriding inner ones.
+//
+// Parameters:
+//   - unpacked (*hqgoerrors.UnpackedError): the unpacked error
+//
+// Returns:
+//   - fields (map[string]any): the merged fields
+func fieldsOf(unpacked *hqgoerrors.UnpackedError) (fields map[string]any) {
+	fields = maps.Clone(unpacked.ErrRoot.Fields)
+
+	if fields == nil {
+		fields = map[string]any{}
+	}
+
+	for i := len(unpacked.ErrChain) - 1; i >= 0; i-- {
+		maps.Copy(fields, unpacked.ErrChain[i].Fields)
+	}
+
+	return
+}
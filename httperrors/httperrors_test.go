@@ -0,0 +1,235 @@
+package httperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponder_Problem(t *testing.T) {
+	t.Parallel()
+
+	responder := NewResponder(
+		WithTypeBaseURI("https://example.com/problems/"),
+		WithStatus("NotFound", http.StatusNotFound),
+		WithFields("user_id", "request_id"),
+	)
+
+	t.Run("typed error", func(t *testing.T) {
+		t.Parallel()
+
+		err := hqgoerrors.New("user not found", hqgoerrors.WithType("NotFound"), hqgoerrors.WithField("user_id", 42), hqgoerrors.WithField("query", "SELECT 1"))
+		err = hqgoerrors.Wrap(err, "loading profile", hqgoerrors.WithField("request_id", "abc"))
+
+		problem := responder.Problem(err)
+
+		require.NotNil(t, problem)
+		assert.Equal(t, "https://example.com/problems/NotFound", problem.Type)
+		assert.Equal(t, "NotFound", problem.Title)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+		assert.Equal(t, "[REDACTED]: [REDACTED]", problem.Detail)
+		assert.Equal(t, map[string]any{"user_id": hqgoerrors.RedactedPlaceholder, "request_id": hqgoerrors.RedactedPlaceholder}, problem.Extensions)
+	})
+
+	t.Run("safe parts", func(t *testing.T) {
+		t.Parallel()
+
+		responder := NewResponder(WithFields("user_id", "token", "query"))
+
+		err := hqgoerrors.NewSafe("user not found", hqgoerrors.WithSafeField("user_id", 42), hqgoerrors.WithSafeField("token", "t-1"), hqgoerrors.WithField("query", "SELECT 1"))
+		err = hqgoerrors.Wrap(err, "loading profile")
+
+		problem := responder.Problem(err)
+
+		require.NotNil(t, problem)
+		assert.Equal(t, "[REDACTED]: user not found", problem.Detail)
+		assert.Equal(t, map[string]any{"user_id": 42, "token": hqgoerrors.RedactedPlaceholder, "query": hqgoerrors.RedactedPlaceholder}, problem.Extensions)
+	})
+
+	t.Run("joined error", func(t *testing.T) {
+		t.Parallel()
+
+		err := hqgoerrors.JoinWith([]error{errors.New("a"), errors.New("b")}, hqgoerrors.WithType("NotFound"))
+
+		problem := responder.Problem(err)
+
+		require.NotNil(t, problem)
+		assert.Equal(t, "NotFound", problem.Title)
+		assert.Equal(t, http.StatusNotFound, problem.Status)
+	})
+
+	t.Run("child type", func(t *testing.T) {
+		t.Parallel()
+
+		child := hqgoerrors.RegisterType("NotFound.User", "NotFound")
+		grandchild := hqgoerrors.RegisterType("NotFound.User.Deleted", child)
+
+		for _, errType := range []hqgoerrors.Type{child, grandchild} {
+			problem := responder.Problem(hqgoerrors.New("user not found", hqgoerrors.WithType(errType)))
+
+			require.NotNil(t, problem)
+			assert.Equal(t, string(errType), problem.Title)
+			assert.Equal(t, http.StatusNotFound, problem.Status)
+		}
+	})
+
+	t.Run("untyped error", func(t *testing.T) {
+		t.Parallel()
+
+		problem := responder.Problem(errors.New("boom"))
+
+		require.NotNil(t, problem)
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, http.StatusText(http.StatusInternalServerError), problem.Title)
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Nil(t, problem.Extensions)
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, responder.Problem(nil))
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		debug bool
+	}{
+		{name: "without debug", debug: false},
+		{name: "with debug", debug: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ofs := []OptionFunc{
+				WithTypeBaseURI("https://example.com/problems/"),
+				WithStatus("Conflict", http.StatusConflict),
+				WithFields("order_id"),
+				WithDebug(tt.debug),
+			}
+
+			responder := NewResponder(ofs...)
+
+			original := hqgoerrors.NewSafe("order exists", hqgoerrors.WithType("Conflict"), hqgoerrors.WithSafeField("order_id", "o-1"))
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_ = responder.Write(w, original)
+			}))
+
+			defer server.Close()
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL, http.NoBody)
+
+			require.NoError(t, err)
+
+			res, err := http.DefaultClient.Do(req)
+
+			require.NoError(t, err)
+
+			defer res.Body.Close()
+
+			assert.Equal(t, http.StatusConflict, res.StatusCode)
+			assert.Equal(t, ContentType, res.Header.Get("Content-Type"))
+
+			decoded, err := Decode(res, ofs...)
+
+			require.NoError(t, err)
+			require.Error(t, decoded)
+
+			var e hqgoerrors.Error
+
+			require.ErrorAs(t, decoded, &e)
+			assert.Equal(t, hqgoerrors.Type("Conflict"), e.Type())
+			assert.Equal(t, "o-1", e.Fields()["order_id"])
+			assert.Equal(t, original.Error(), decoded.Error())
+			assert.Equal(t, tt.debug, hqgoerrors.IsRemote(decoded))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not a problem document", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+
+		recorder.Header().Set("Content-Type", "application/json")
+		recorder.WriteHeader(http.StatusOK)
+
+		_, _ = recorder.WriteString(`{}`)
+
+		res := recorder.Result()
+
+		defer res.Body.Close()
+
+		decoded, err := Decode(res)
+
+		require.NoError(t, err)
+		assert.NoError(t, decoded)
+	})
+
+	t.Run("malformed problem document", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := httptest.NewRecorder()
+
+		recorder.Header().Set("Content-Type", ContentType+"; charset=utf-8")
+		recorder.WriteHeader(http.StatusBadRequest)
+
+		_, _ = recorder.WriteString(`{`)
+
+		res := recorder.Result()
+
+		defer res.Body.Close()
+
+		_, err := Decode(res)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestProblem_JSON(t *testing.T) {
+	t.Parallel()
+
+	problem := &Problem{
+		Type:       "https://example.com/problems/NotFound",
+		Title:      "NotFound",
+		Status:     http.StatusNotFound,
+		Detail:     "user not found",
+		Extensions: map[string]any{"user_id": "u-1", "title": "ignored"},
+	}
+
+	data, err := json.Marshal(problem)
+
+	require.NoError(t, err)
+
+	var document map[string]any
+
+	require.NoError(t, json.Unmarshal(data, &document))
+
+	assert.Equal(t, "NotFound", document["title"])
+	assert.Equal(t, "u-1", document["user_id"])
+
+	decoded := &Problem{}
+
+	require.NoError(t, json.Unmarshal(data, decoded))
+
+	assert.Equal(t, problem.Type, decoded.Type)
+	assert.Equal(t, problem.Status, decoded.Status)
+	assert.Equal(t, problem.Detail, decoded.Detail)
+	assert.Equal(t, map[string]any{"user_id": "u-1"}, decoded.Extensions)
+}
@@ -0,0 +1,254 @@
+package errors
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// AttrKey is the key used by Attr for the error attribute.
+const AttrKey = "error"
+
+// LogHandler is a slog.Handler that expands error attributes into structured groups.
+// It wraps another handler and, before delegating to it, replaces the value of every
+// attribute holding an error with the group produced by its Formatter.
+//
+// Fields:
+//   - handler (slog.Handler): the wrapped handler that renders the records
+//   - formatter (*Formatter): the formatter used to expand error attributes
+type LogHandler struct {
+	handler   slog.Handler
+	formatter *Formatter
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the logging call
+//   - level (slog.Level): the level of the record
+//
+// Returns:
+//   - enabled (bool): true if the wrapped handler is enabled for level
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) (enabled bool) {
+	enabled = h.handler.Enabled(ctx, level)
+
+	return
+}
+
+// Handle expands the error attributes of the record and passes it to the wrapped handler.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the logging call
+//   - record (slog.Record): the record to handle
+//
+// Returns:
+//   - err (error): the error returned by the wrapped handler
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) (err error) {
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(h.expand(attr))
+
+		return true
+	})
+
+	err = h.handler.Handle(ctx, expanded)
+
+	return
+}
+
+// WithAttrs returns a new LogHandler whose wrapped handler has the given attributes,
+// with their error attributes expanded.
+//
+// Parameters:
+//   - attrs ([]slog.Attr): the attributes to add
+//
+// Returns:
+//   - handler (slog.Handler): the new handler
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) (handler slog.Handler) {
+	expanded := make([]slog.Attr, len(attrs))
+
+	for i, attr := range attrs {
+		expanded[i] = h.expand(attr)
+	}
+
+	handler = &LogHandler{
+		handler:   h.handler.WithAttrs(expanded),
+		formatter: h.formatter,
+	}
+
+	return
+}
+
+// WithGroup returns a new LogHandler whose wrapped handler opens the given group.
+//
+// Parameters:
+//   - name (string): the name of the group
+//
+// Returns:
+//   - handler (slog.Handler): the new handler
+func (h *LogHandler) WithGroup(name string) (handler slog.Handler) {
+	handler = &LogHandler{
+		handler:   h.handler.WithGroup(name),
+		formatter: h.formatter,
+	}
+
+	return
+}
+
+// expand replaces the value of an attribute holding an error with its formatted group.
+// Groups are expanded recursively; other attributes are returned unchanged.
+//
+// Parameters:
+//   - attr (slog.Attr): the attribute to expand
+//
+// Returns:
+//   - (slog.Attr): the expanded attribute
+func (h *LogHandler) expand(attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		if err, ok := attr.Value.Any().(error); ok && err != nil {
+			attr.Value = h.formatter.LogValue(err)
+		}
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, len(group))
+
+		for i, a := range group {
+			expanded[i] = h.expand(a)
+		}
+
+		attr.Value = slog.GroupValue(expanded...)
+	case slog.KindBool, slog.KindDuration, slog.KindFloat64, slog.KindInt64, slog.KindString, slog.KindTime, slog.KindUint64:
+	}
+
+	return attr
+}
+
+// NewLogHandler creates a LogHandler wrapping handler.
+// Error attributes are expanded by a Formatter configured with the given options,
+// so slog's handlers render them with the same options as ToString and ToJSON.
+//
+// Parameters:
+//   - handler (slog.Handler): the handler to wrap
+//   - ofs (...FormatterOptionFunc): optional formatter configuration
+//
+// Returns:
+//   - h (*LogHandler): the new handler
+func NewLogHandler(handler slog.Handler, ofs ...FormatterOptionFunc) (h *LogHandler) {
+	h = &LogHandler{
+		handler:   handler,
+		formatter: NewFormatter(ofs...),
+	}
+
+	return
+}
+
+// LogValue formats the error as a slog group value.
+// The error is redacted once, in redacted mode, and the group holds its message under
+// "message", followed by the entries produced by JSON (e.g. "root", "chain" and
+// "external", each with their type, fields and, if traces are enabled, stack).
+//
+// Parameters:
+//   - err (error): the error to format
+//
+// Returns:
+//   - value (slog.Value): the group value, or an empty group if err is nil
+func (f *Formatter) LogValue(err error) (value slog.Value) {
+	if err == nil {
+		value = slog.GroupValue()
+
+		return
+	}
+
+	if f.options.Redact {
+		err = redact(err, f.options.SensitiveFields)
+	}
+
+	attrs := []slog.Attr{slog.String("message", err.Error())}
+
+	attrs = append(attrs, toLogValue(f.unpackJSON(err)).Group()...)
+
+	value = slog.GroupValue(attrs...)
+
+	return
+}
+
+// toLogValue converts a value produced by Formatter.JSON into a slog.Value.
+// Maps become groups with their keys in sorted order, slices become groups keyed
+// by index, and any other value is converted by slog.AnyValue.
+//
+// Parameters:
+//   - v (any): the value to convert
+//
+// Returns:
+//   - (slog.Value): the converted value
+func toLogValue(v any) slog.Value {
+	switch x := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+
+		for k := range x {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		attrs := make([]slog.Attr, len(keys))
+
+		for i, k := range keys {
+			attrs[i] = slog.Attr{Key: k, Value: toLogValue(x[k])}
+		}
+
+		return slog.GroupValue(attrs...)
+	case []map[string]any:
+		attrs := make([]slog.Attr, len(x))
+
+		for i, item := range x {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: toLogValue(item)}
+		}
+
+		return slog.GroupValue(attrs...)
+	case []any:
+		attrs := make([]slog.Attr, len(x))
+
+		for i, item := range x {
+			attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: toLogValue(item)}
+		}
+
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// logValue backs the slog.LogValuer implementations of the package's error types.
+// It formats err with a default Formatter, which leaves stack traces out; use Attr
+// or LogHandler with FormatWithTrace to include them.
+//
+// Parameters:
+//   - err (error): the error to format
+//
+// Returns:
+//   - (slog.Value): the group value
+func logValue(err error) slog.Value {
+	return NewFormatter().LogValue(err)
+}
+
+// Attr returns a slog attribute with key AttrKey holding err formatted as a group.
+// It is a convenience for logger.Error("msg", errors.Attr(err, errors.FormatWithTrace())).
+//
+// Parameters:
+//   - err (error): the error to format
+//   - ofs (...FormatterOptionFunc): optional configuration
+//
+// Returns:
+//   - attr (slog.Attr): the error attribute
+func Attr(err error, ofs ...FormatterOptionFunc) (attr slog.Attr) {
+	formatter := NewFormatter(ofs...)
+
+	attr = slog.Attr{Key: AttrKey, Value: formatter.LogValue(err)}
+
+	return
+}
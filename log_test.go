@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("root error", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("root", WithType("ROOT_TYPE"), WithField("key", "value"))
+
+		value := err.(slog.LogValuer).LogValue()
+
+		require.Equal(t, slog.KindGroup, value.Kind())
+
+		attrs := value.Group()
+
+		require.Len(t, attrs, 2)
+		assert.Equal(t, "message", attrs[0].Key)
+		assert.Equal(t, "root", attrs[0].Value.String())
+		assert.Equal(t, "root", attrs[1].Key)
+		assert.Equal(t, slog.KindGroup, attrs[1].Value.Kind())
+	})
+
+	t.Run("json handler", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(New("root", WithType("ROOT_TYPE"), WithField("key", "value")), "wrapper")
+
+		var buf bytes.Buffer
+
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+		logger.Error("failed", "error", err)
+
+		var record map[string]any
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+		logged := record["error"].(map[string]any)
+
+		assert.Equal(t, "wrapper: root", logged["message"])
+		assert.Equal(t, "wrapper", logged["chain"].(map[string]any)["0"].(map[string]any)["message"])
+		assert.Equal(t, "ROOT_TYPE", logged["root"].(map[string]any)["type"])
+		assert.Equal(t, map[string]any{"key": "value"}, logged["root"].(map[string]any)["fields"])
+		assert.NotContains(t, logged["root"], "stack")
+	})
+
+	t.Run("redacted", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(NewSafe("query failed", WithField("password", "hunter2"), WithField("user", "alice")), "SELECT 1")
+
+		attrs := NewFormatter(FormatRedacted()).LogValue(err).Group()
+
+		require.Len(t, attrs, 3)
+		assert.Equal(t, RedactedPlaceholder+": query failed", attrs[0].Value.String())
+		assert.Equal(t, toLogValue(NewFormatter(FormatRedacted()).JSON(err)).Group(), attrs[1:])
+	})
+
+	t.Run("nil error", func(t *testing.T) {
+		t.Parallel()
+
+		value := NewFormatter().LogValue(nil)
+
+		assert.Empty(t, value.Group())
+	})
+}
+
+func TestAttr(t *testing.T) {
+	t.Parallel()
+
+	err := New("root")
+
+	attr := Attr(err, FormatWithTrace())
+
+	assert.Equal(t, AttrKey, attr.Key)
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	logger.Error("failed", attr)
+
+	var record map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Contains(t, record[AttrKey].(map[string]any)["root"], "stack")
+}
+
+func TestLogHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expands error attributes", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+
+		logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil), FormatWithTrace()))
+
+		logger.With("base", New("base")).WithGroup("request").Error("failed",
+			"error", Wrap(New("root"), "wrapper"),
+			"external", errors.New("external"),
+			slog.Group("nested", "error", New("nested")),
+			"count", 1,
+		)
+
+		var record map[string]any
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+		assert.Contains(t, record["base"].(map[string]any)["root"], "stack")
+
+		request := record["request"].(map[string]any)
+
+		assert.Contains(t, request["error"].(map[string]any)["root"], "stack")
+		assert.Equal(t, "external", request["external"].(map[string]any)["external"].(map[string]any)["message"])
+		assert.Equal(t, "nested", request["nested"].(map[string]any)["error"].(map[string]any)["message"])
+		assert.InDelta(t, 1, request["count"], 0)
+	})
+
+	t.Run("text handler", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+
+		logger := slog.New(NewLogHandler(slog.NewTextHandler(&buf, nil)))
+
+		logger.Error("failed", "error", Join(New("error1"), New("error2")))
+
+		assert.Contains(t, buf.String(), "error.type=joined")
+		assert.Contains(t, buf.String(), "error.errors.0.root.message=error1")
+		assert.Contains(t, buf.String(), "error.errors.1.root.message=error2")
+	})
+
+	t.Run("respects level", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+
+		logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError})))
+
+		logger.Info("ignored", "error", New("root"))
+
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,394 @@
+// Package metrics counts errors by Type, by the function that created their root error,
+// and by how they were created (new, wrap or join), using the standard library only.
+//
+// A Collector is fed either by the package's creation path, once installed with
+// Collector.Install, or explicitly with Collector.Record. It implements expvar.Var,
+// so it can be published with expvar.Publish, and http.Handler, serving the counts
+// in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+// ContentType is the media type of the Prometheus text exposition format served by Collector.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultName is the default name of the counter exposed by Collector.
+const DefaultName = "hq_go_errors_total"
+
+// Key identifies a counter of a Collector.
+//
+// Fields:
+//   - Type (hqgoerrors.Type): the type of the error's root, or of the joined error for joins
+//   - Function (string): the fully qualified function that created the root error,
+//     or empty if unknown (e.g. without stack trace, or for external errors)
+//   - Kind (hqgoerrors.EventKind): how the error was created
+type Key struct {
+	Type     hqgoerrors.Type
+	Function string
+	Kind     hqgoerrors.EventKind
+}
+
+// Options holds configuration for a Collector.
+//
+// Fields:
+//   - Name (string): name of the counter in the Prometheus output (default: DefaultName)
+//   - Help (string): help text of the counter in the Prometheus output
+type Options struct {
+	Name string
+	Help string
+}
+
+// OptionFunc is a function type for configuring Options.
+// Used with NewCollector to set custom options.
+type OptionFunc func(options *Options)
+
+// Collector counts errors by Key. It is safe for concurrent use.
+//
+// Fields:
+//   - mu (sync.RWMutex): guards counts
+//   - counts (map[Key]uint64): the counters
+//   - options (*Options): the configuration options
+type Collector struct {
+	mu      sync.RWMutex
+	counts  map[Key]uint64
+	options *Options
+}
+
+var (
+	_ expvar.Var   = (*Collector)(nil)
+	_ http.Handler = (*Collector)(nil)
+)
+
+// Install registers the collector as a creation hook (see hqgoerrors.OnCreate), so every
+// error created by the package is counted, with the kind of its creation.
+//
+// Returns:
+//   - remove (func()): unregisters the collector
+func (c *Collector) Install() (remove func()) {
+	remove = hqgoerrors.OnCreate(c.Observe)
+
+	return
+}
+
+// Observe counts the error of a creation event. It is the hook registered by Install.
+//
+// Parameters:
+//   - event (hqgoerrors.Event): the creation event
+func (c *Collector) Observe(event hqgoerrors.Event) {
+	errType, function := attribute(event.Err)
+
+	c.add(Key{Type: errType, Function: function, Kind: event.Kind})
+}
+
+// Record counts an error explicitly, e.g. errors from other packages, or errors at the
+// point they are handled rather than created. The kind is derived from the outermost
+// error: EventJoin for multi-errors, EventWrap for errors wrapping another one, and
+// EventNew otherwise. Nil errors are ignored.
+//
+// Parameters:
+//   - err (error): the error to count
+func (c *Collector) Record(err error) {
+	if err == nil {
+		return
+	}
+
+	kind := hqgoerrors.EventNew
+
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		kind = hqgoerrors.EventJoin
+	case interface{ Unwrap() error }:
+		if x.Unwrap() != nil {
+			kind = hqgoerrors.EventWrap
+		}
+	}
+
+	errType, function := attribute(err)
+
+	c.add(Key{Type: errType, Function: function, Kind: kind})
+}
+
+// Counts returns a snapshot of the counters.
+//
+// Returns:
+//   - counts (map[Key]uint64): the counters, by key
+func (c *Collector) Counts() (counts map[Key]uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts = maps.Clone(c.counts)
+
+	return
+}
+
+// Reset sets all counters back to zero.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.counts)
+}
+
+// String implements expvar.Var, returning the counters as a JSON object with the total
+// and the counts by type, by function and by kind:
+//
+//	{"total": 3, "types": {"NOT_FOUND": 2, "": 1}, "functions": {...}, "kinds": {"new": 2, "wrap": 1}}
+//
+// Returns:
+//   - s (string): the JSON encoding of the counters
+func (c *Collector) String() (s string) {
+	snapshot := struct {
+		Total     uint64            `json:"total"`
+		Types     map[string]uint64 `json:"types"`
+		Functions map[string]uint64 `json:"functions"`
+		Kinds     map[string]uint64 `json:"kinds"`
+	}{
+		Types:     map[string]uint64{},
+		Functions: map[string]uint64{},
+		Kinds:     map[string]uint64{},
+	}
+
+	for key, count := range c.Counts() {
+		snapshot.Total += count
+		snapshot.Types[string(key.Type)] += count
+		snapshot.Functions[key.Function] += count
+		snapshot.Kinds[string(key.Kind)] += count
+	}
+
+	data, _ := json.Marshal(snapshot)
+
+	s = string(data)
+
+	return
+}
+
+// ServeHTTP implements http.Handler, writing the counters in the Prometheus text
+// exposition format (see WritePrometheus).
+//
+// Parameters:
+//   - w (http.ResponseWriter): the response writer
+//   - r (*http.Request): the request (unused)
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+
+	_ = c.WritePrometheus(w)
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format, as a single
+// counter with "type", "function" and "kind" labels, sorted by labels:
+//
+//	# HELP hq_go_errors_total Errors created, by type, root function and kind.
+//	# TYPE hq_go_errors_total counter
+//	hq_go_errors_total{type="NOT_FOUND",function="main.load",kind="new"} 2
+//
+// Parameters:
+//   - w (io.Writer): where to write
+//
+// Returns:
+//   - err (error): any error from writing
+func (c *Collector) WritePrometheus(w io.Writer) (err error) {
+	counts := c.Counts()
+
+	keys := slices.SortedFunc(maps.Keys(counts), func(a, b Key) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Function, b.Function), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, "# HELP %s %s\n", c.options.Name, escapeHelp(c.options.Help))
+	fmt.Fprintf(buf, "# TYPE %s counter\n", c.options.Name)
+
+	for _, key := range keys {
+		fmt.Fprintf(buf, "%s{type=\"%s\",function=\"%s\",kind=\"%s\"} %d\n",
+			c.options.Name,
+			escapeLabel(string(key.Type)),
+			escapeLabel(key.Function),
+			escapeLabel(string(key.Kind)),
+			counts[key],
+		)
+	}
+
+	err = buf.Flush()
+
+	return
+}
+
+// add increments the counter of a key.
+//
+// Parameters:
+//   - key (Key): the key of the counter
+func (c *Collector) add(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[key]++
+}
+
+// NewCollector creates a Collector with the given options.
+// It starts counting once installed (see Install) or fed with Record.
+//
+// Parameters:
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - collector (*Collector): the new collector instance
+func NewCollector(ofs ...OptionFunc) (collector *Collector) {
+	collector = &Collector{
+		counts:  map[Key]uint64{},
+		options: newOptions(ofs...),
+	}
+
+	return
+}
+
+// WithName returns an option function setting the name of the counter in the Prometheus output.
+//
+// Parameters:
+//   - name (string): the metric name, e.g. "myservice_errors_total"
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithName(name string) (f OptionFunc) {
+	return func(options *Options) {
+		options.Name = name
+	}
+}
+
+// WithHelp returns an option function setting the help text of the counter in the Prometheus output.
+//
+// Parameters:
+//   - help (string): the help text
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithHelp(help string) (f OptionFunc) {
+	return func(options *Options) {
+		options.Help = help
+	}
+}
+
+// newOptions creates Options with the defaults and applies the option functions.
+//
+// Parameters:
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - options (*Options): the configured options
+func newOptions(ofs ...OptionFunc) (options *Options) {
+	options = &Options{
+		Name: DefaultName,
+		Help: "Errors created, by type, root function and kind.",
+	}
+
+	for _, f := range ofs {
+		f(options)
+	}
+
+	return
+}
+
+// attribute finds the error an error is attributed to, walking its chain down to the
+// root, or to the first multi-error, whose own type is used; wrapped errors are thereby
+// attributed to the type of their root.
+//
+// Parameters:
+//   - err (error): the error to attribute
+//
+// Returns:
+//   - errType (hqgoerrors.Type): the type of the root, or empty if untyped or external
+//   - function (string): the function that created the root, or empty if unknown
+func attribute(err error) (errType hqgoerrors.Type, function string) {
+	var target hqgoerrors.Error
+
+	for _, node := range hqgoerrors.Chain(err) {
+		if e, ok := node.(hqgoerrors.Error); ok {
+			target = e
+		}
+	}
+
+	if target == nil {
+		return
+	}
+
+	errType = target.Type()
+
+	if PCs := target.StackFrames(); len(PCs) > 0 {
+		function = functionOf(PCs[0])
+	}
+
+	return
+}
+
+// functions memoizes the resolution of program counters into function names.
+var functions sync.Map
+
+// functionOf resolves a program counter, as captured by runtime.Callers, into the fully
+// qualified name of the function it belongs to, memoizing the result.
+//
+// Parameters:
+//   - PC (uintptr): the program counter
+//
+// Returns:
+//   - function (string): the function name
+func functionOf(PC uintptr) (function string) {
+	if cached, ok := functions.Load(PC); ok {
+		function, _ = cached.(string)
+
+		return
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{PC}).Next()
+
+	function = frame.Function
+
+	functions.Store(PC, function)
+
+	return
+}
+
+// labelEscaper escapes label values as required by the Prometheus text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value.
+//
+// Parameters:
+//   - value (string): the label value
+//
+// Returns:
+//   - escaped (string): the escaped value
+func escapeLabel(value string) (escaped string) {
+	escaped = labelEscaper.Replace(value)
+
+	return
+}
+
+// helpEscaper escapes help texts as required by the Prometheus text exposition format.
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// escapeHelp escapes a help text.
+//
+// Parameters:
+//   - help (string): the help text
+//
+// Returns:
+//   - escaped (string): the escaped text
+func escapeHelp(help string) (escaped string) {
+	escaped = helpEscaper.Replace(help)
+
+	return
+}
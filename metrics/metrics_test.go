@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFunction = "github.com/hueristiq/hq-go-errors/metrics.newNotFound"
+
+func newNotFound() error {
+	return hqgoerrors.New("not found", hqgoerrors.WithType("NOT_FOUND"))
+}
+
+func TestCollector_Record(t *testing.T) {
+	t.Parallel()
+
+	collector := NewCollector()
+
+	err := newNotFound()
+
+	collector.Record(err)
+	collector.Record(hqgoerrors.Wrap(err, "loading"))
+	collector.Record(fmt.Errorf("handler: %w", hqgoerrors.Wrap(err, "loading")))
+	collector.Record(hqgoerrors.JoinWith([]error{err, err}, hqgoerrors.WithType("BATCH")))
+	collector.Record(errors.New("external"))
+	collector.Record(hqgoerrors.Wrap(errors.New("external"), "wrapped", hqgoerrors.WithType("IO")))
+	collector.Record(nil)
+
+	counts := collector.Counts()
+
+	assert.Equal(t, uint64(1), counts[Key{Type: "NOT_FOUND", Function: testFunction, Kind: hqgoerrors.EventNew}])
+	assert.Equal(t, uint64(2), counts[Key{Type: "NOT_FOUND", Function: testFunction, Kind: hqgoerrors.EventWrap}])
+	assert.Equal(t, uint64(1), counts[Key{Kind: hqgoerrors.EventNew}])
+	assert.Len(t, counts, 5)
+
+	for key := range counts {
+		switch key.Type {
+		case "BATCH":
+			assert.Equal(t, hqgoerrors.EventJoin, key.Kind)
+			assert.Contains(t, key.Function, "TestCollector_Record")
+		case "IO":
+			assert.Equal(t, hqgoerrors.EventWrap, key.Kind)
+			assert.Contains(t, key.Function, "TestCollector_Record")
+		}
+	}
+
+	collector.Reset()
+
+	assert.Empty(t, collector.Counts())
+}
+
+//nolint:paralleltest // installs a package-level creation hook
+func TestCollector_Install(t *testing.T) {
+	collector := NewCollector()
+
+	remove := collector.Install()
+
+	err := newNotFound()
+	err = hqgoerrors.Wrap(err, "loading")
+	_ = hqgoerrors.Join(err, errors.New("other"))
+
+	remove()
+
+	_ = newNotFound()
+
+	counts := collector.Counts()
+
+	assert.Equal(t, uint64(1), counts[Key{Type: "NOT_FOUND", Function: testFunction, Kind: hqgoerrors.EventNew}])
+	assert.Equal(t, uint64(1), counts[Key{Type: "NOT_FOUND", Function: testFunction, Kind: hqgoerrors.EventWrap}])
+	assert.Len(t, counts, 3)
+}
+
+func TestCollector_String(t *testing.T) {
+	t.Parallel()
+
+	collector := NewCollector()
+
+	collector.Record(newNotFound())
+	collector.Record(hqgoerrors.Wrap(newNotFound(), "loading"))
+	collector.Record(errors.New("external"))
+
+	var snapshot struct {
+		Total     uint64            `json:"total"`
+		Types     map[string]uint64 `json:"types"`
+		Functions map[string]uint64 `json:"functions"`
+		Kinds     map[string]uint64 `json:"kinds"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(collector.String()), &snapshot))
+
+	assert.Equal(t, uint64(3), snapshot.Total)
+	assert.Equal(t, map[string]uint64{"NOT_FOUND": 2, "": 1}, snapshot.Types)
+	assert.Equal(t, map[string]uint64{testFunction: 2, "": 1}, snapshot.Functions)
+	assert.Equal(t, map[string]uint64{"new": 2, "wrap": 1}, snapshot.Kinds)
+
+	expvar.Publish("hq_go_errors_test", collector)
+
+	assert.Equal(t, collector.String(), expvar.Get("hq_go_errors_test").String())
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	collector := NewCollector(WithName("test_errors_total"), WithHelp("Test errors.\nPer type."))
+
+	collector.Record(newNotFound())
+	collector.Record(newNotFound())
+	collector.Record(hqgoerrors.New("bad", hqgoerrors.WithType(`say "hi"\`), hqgoerrors.WithoutStack()))
+
+	rec := httptest.NewRecorder()
+
+	collector.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		`# HELP test_errors_total Test errors.\nPer type.`,
+		`# TYPE test_errors_total counter`,
+		`test_errors_total{type="NOT_FOUND",function="` + testFunction + `",kind="new"} 2`,
+		`test_errors_total{type="say \"hi\"\\",function="",kind="new"} 1`,
+		``,
+	}, "\n"), rec.Body.String())
+}
+
+func BenchmarkCollector_Observe(b *testing.B) {
+	collector := NewCollector()
+
+	remove := collector.Install()
+
+	defer remove()
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = newNotFound()
+	}
+}
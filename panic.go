@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// TypePanic is the type of errors created from recovered panics.
+const TypePanic Type = "panic"
+
+// PanicField is the key of the field holding the recovered panic value.
+const PanicField = "panic"
+
+// FromPanic converts a recovered panic value into an error.
+// The returned error is a root error of type TypePanic that carries the panic value
+// in the PanicField field. If the value is itself an error, it becomes the cause,
+// so Is and As still see it; otherwise the message is "panic: " followed by the value.
+//
+// When called from a deferred function while the goroutine is panicking, the stack
+// trace starts at the function that panicked rather than at the deferred function.
+//
+// Parameters:
+//   - v (any): the value returned by recover
+//
+// Returns:
+//   - err (error): the panic error, or nil if v is nil
+func FromPanic(v any) (err error) {
+	e := fromPanic(v, 4) // panicCallers(4) skips this method (FromPanic), fromPanic, panicCallers, and runtime.Callers
+	if e == nil {
+		return
+	}
+
+	err = e
+
+	return
+}
+
+// Recover recovers from a panic and stores it in *errp as an error built by FromPanic.
+// It must be deferred directly, since recover only stops a panic when called by the
+// deferred function itself:
+//
+//	func run() (err error) {
+//		defer errors.Recover(&err)
+//
+//		...
+//	}
+//
+// If *errp already holds an error, the panic error is joined to it, so neither is lost.
+// A nil errp recovers and discards the panic.
+//
+// Parameters:
+//   - errp (*error): where to store the panic error
+func Recover(errp *error) {
+	v := recover()
+	if v == nil || errp == nil {
+		return
+	}
+
+	e := fromPanic(v, 4) // panicCallers(4) skips this method (Recover), fromPanic, panicCallers, and runtime.Callers
+
+	*errp = Join(*errp, e)
+}
+
+// Go runs fn in a new goroutine and delivers its result on the returned channel.
+// A panic in fn is recovered and delivered as an error built by FromPanic, so a
+// panicking worker does not crash the program.
+//
+// The channel is buffered and closed after the single result is sent, so it can be
+// abandoned without leaking the goroutine. Use Wait to combine the results of several
+// workers into one joined error.
+//
+// Parameters:
+//   - fn (func() error): the function to run
+//
+// Returns:
+//   - ch (<-chan error): channel receiving fn's error or panic error, nil if fn succeeds
+func Go(fn func() error) (ch <-chan error) {
+	c := make(chan error, 1)
+
+	go func() {
+		var err error
+
+		defer func() {
+			c <- err
+
+			close(c)
+		}()
+
+		defer Recover(&err)
+
+		err = fn()
+	}()
+
+	ch = c
+
+	return
+}
+
+// Wait receives one result from each channel, typically returned by Go, and joins them.
+// It blocks until every channel has delivered a result or been closed.
+//
+// Parameters:
+//   - chs (...<-chan error): the channels to wait on
+//
+// Returns:
+//   - err (error): the joined non-nil results, or nil if there are none
+func Wait(chs ...<-chan error) (err error) {
+	errs := make([]error, 0, len(chs))
+
+	for _, ch := range chs {
+		errs = append(errs, <-ch)
+	}
+
+	err = Join(errs...)
+
+	return
+}
+
+// fromPanic builds the root error for a recovered panic value.
+//
+// Parameters:
+//   - v (any): the value returned by recover
+//   - skip (int): number of frames to omit when the goroutine is not panicking
+//
+// Returns:
+//   - e (*root): the panic error, or nil if v is nil
+func fromPanic(v any, skip int) (e *root) {
+	if v == nil {
+		return
+	}
+
+	e = &root{
+		errType: TypePanic,
+		fields:  map[string]any{PanicField: v},
+	}
+
+	if cause, ok := v.(error); ok {
+		e.message = "panic"
+		e.cause = cause
+	} else {
+		e.message = fmt.Sprintf("panic: %v", v)
+	}
+
+	e.trace, e.isGlobal = e.capture.panicCallers(skip, e.errType)
+
+	notify(EventNew, e)
+
+	return
+}
@@ -0,0 +1,238 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func panicWith(v any) {
+	panic(v)
+}
+
+func dereferenceNil() int {
+	var p *int
+
+	return *p
+}
+
+func recoverFrom(fn func()) (err error) {
+	defer Recover(&err)
+
+	fn()
+
+	return
+}
+
+func TestFromPanic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil value", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, FromPanic(nil))
+	})
+
+	t.Run("non-error value", func(t *testing.T) {
+		t.Parallel()
+
+		err := FromPanic(42)
+
+		require.Error(t, err)
+		assert.Equal(t, "panic: 42", err.Error())
+		assert.Equal(t, TypePanic, err.(*root).Type())
+		assert.Equal(t, 42, err.(*root).Fields()[PanicField])
+		assert.True(t, IsType(err, TypePanic))
+	})
+
+	t.Run("error value", func(t *testing.T) {
+		t.Parallel()
+
+		cause := New("boom")
+
+		err := FromPanic(cause)
+
+		require.Error(t, err)
+		assert.Equal(t, "panic: boom", err.Error())
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, cause, err.(*root).Fields()[PanicField])
+	})
+
+	t.Run("outside a panic", func(t *testing.T) {
+		t.Parallel()
+
+		frames := Unpack(FromPanic("value")).ErrRoot.Stack
+
+		require.NotEmpty(t, frames)
+		assert.Contains(t, frames[0].Name, "TestFromPanic")
+	})
+
+	t.Run("inside a deferred function", func(t *testing.T) {
+		t.Parallel()
+
+		var err error
+
+		func() {
+			defer func() {
+				err = FromPanic(recover())
+			}()
+
+			panicWith("value")
+		}()
+
+		require.Error(t, err)
+
+		frames := Unpack(err).ErrRoot.Stack
+
+		require.NotEmpty(t, frames)
+		assert.Contains(t, frames[0].Name, "panicWith")
+	})
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no panic", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, recoverFrom(func() {}))
+	})
+
+	t.Run("panic stack", func(t *testing.T) {
+		t.Parallel()
+
+		err := recoverFrom(func() { panicWith("value") })
+
+		require.Error(t, err)
+		assert.Equal(t, "panic: value", err.Error())
+
+		frames := Unpack(err).ErrRoot.Stack
+
+		require.NotEmpty(t, frames)
+		assert.Contains(t, frames[0].Name, "panicWith")
+
+		for _, frame := range frames {
+			assert.NotEqual(t, "github.com/hueristiq/hq-go-errors.Recover", frame.Function)
+		}
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		t.Parallel()
+
+		err := recoverFrom(func() { _ = dereferenceNil() })
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nil pointer dereference")
+
+		frames := Unpack(err).ErrRoot.Stack
+
+		require.NotEmpty(t, frames)
+		assert.Contains(t, frames[0].Name, "dereferenceNil")
+	})
+
+	t.Run("existing error", func(t *testing.T) {
+		t.Parallel()
+
+		first := New("first")
+
+		err := func() (err error) {
+			defer Recover(&err)
+
+			err = first
+
+			panicWith("value")
+
+			return
+		}()
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, first)
+		assert.True(t, IsType(err, TypePanic))
+		assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+	})
+
+	t.Run("nil destination", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NotPanics(t, func() {
+			defer Recover(nil)
+
+			panicWith("value")
+		})
+	})
+}
+
+func TestGo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("result", func(t *testing.T) {
+		t.Parallel()
+
+		expected := New("failed")
+
+		assert.NoError(t, <-Go(func() error { return nil }))
+		assert.Equal(t, expected, <-Go(func() error { return expected }))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		ch := Go(func() error {
+			panicWith("value")
+
+			return nil
+		})
+
+		err := <-ch
+
+		require.Error(t, err)
+		assert.True(t, IsType(err, TypePanic))
+		assert.Contains(t, Unpack(err).ErrRoot.Stack[0].Name, "panicWith")
+
+		_, open := <-ch
+
+		assert.False(t, open)
+	})
+}
+
+func TestWait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no errors", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wait(
+			Go(func() error { return nil }),
+			Go(func() error { return nil }),
+		)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("panicking workers", func(t *testing.T) {
+		t.Parallel()
+
+		failed := New("failed")
+
+		err := Wait(
+			Go(func() error {
+				panicWith("first")
+
+				return nil
+			}),
+			Go(func() error { return failed }),
+			Go(func() error { return nil }),
+			Go(func() error {
+				panicWith("second")
+
+				return nil
+			}),
+		)
+
+		require.Error(t, err)
+		assert.Equal(t, "panic: first\nfailed\npanic: second", err.Error())
+		assert.ErrorIs(t, err, failed)
+		assert.Len(t, FindAll[*root](err), 3)
+	})
+}
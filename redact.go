@@ -0,0 +1,293 @@
+package errors
+
+import (
+	"regexp"
+)
+
+// RedactedPlaceholder replaces redacted messages and field values.
+const RedactedPlaceholder = "[REDACTED]"
+
+// DefaultSensitiveFields matches the field keys that formatters redact by default,
+// whether or not redaction is enabled (see FormatSensitiveFields).
+var DefaultSensitiveFields = regexp.MustCompile(`(?i)passw(or)?d|secret|token|api[_-]?key|authorization|cookie|credential|private[_-]?key`)
+
+// safety records which parts of an error are safe to show in redacted output.
+// Messages and fields are sensitive unless marked safe at creation time.
+//
+// Fields:
+//   - message (bool): whether the error's own message is safe
+//   - fields (map[string]struct{}): the keys of the fields that are safe
+type safety struct {
+	message bool
+	fields  map[string]struct{}
+}
+
+// safetyOf returns the safety marks of an error being created.
+//
+// Parameters:
+//   - err (Error): the error being configured
+//
+// Returns:
+//   - marks (*safety): the error's safety marks, or nil for other error types
+func safetyOf(err Error) (marks *safety) {
+	switch e := err.(type) {
+	case *root:
+		marks = &e.safe
+	case *wrapped:
+		marks = &e.safe
+	case *joined:
+		marks = &e.safe
+	}
+
+	return
+}
+
+// WithSafeMessage creates an OptionFunc that marks the error's own message as safe,
+// so it is kept in redacted output (see Redact). The messages of the errors it wraps
+// are not affected.
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithSafeMessage() (f OptionFunc) {
+	return func(err Error) {
+		if marks := safetyOf(err); marks != nil {
+			marks.message = true
+		}
+	}
+}
+
+// WithSafeField creates an OptionFunc that adds a field marked as safe, so its value is
+// kept in redacted output (see Redact). Keys matching the formatter's sensitive field
+// pattern are redacted even if marked safe.
+//
+// Parameters:
+//   - key (string): field key
+//   - value (any): field value
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithSafeField(key string, value any) (f OptionFunc) {
+	return func(err Error) {
+		err.SetField(key, value)
+
+		if marks := safetyOf(err); marks != nil {
+			if marks.fields == nil {
+				marks.fields = map[string]struct{}{}
+			}
+
+			marks.fields[key] = struct{}{}
+		}
+	}
+}
+
+// NewSafe creates a new root error like New, with its message marked as safe
+// (see WithSafeMessage).
+//
+// Parameters:
+//   - msg (string): the primary error message, safe to show to users
+//   - ofs (...OptionFunc): configuration options
+//
+// Returns:
+//   - err (error): the newly created error (implements Error interface)
+func NewSafe(msg string, ofs ...OptionFunc) (err error) {
+	e := &root{
+		message: msg,
+		safe:    safety{message: true},
+	}
+
+	for _, f := range ofs {
+		f(e)
+	}
+
+	e.trace, e.isGlobal = e.capture.callers(3, e.errType) // callers(3) skips this method (NewSafe), callers, and runtime.Callers
+
+	notify(EventNew, e)
+
+	err = e
+
+	return
+}
+
+// WrapSafe wraps an error like Wrap, with the new message marked as safe
+// (see WithSafeMessage).
+//
+// Parameters:
+//   - cause (error): the error to wrap
+//   - msg (string): additional context message, safe to show to users
+//   - ofs (...OptionFunc): configuration options
+//
+// Returns:
+//   - err (error): the new wrapping error, or nil if cause is nil
+func WrapSafe(cause error, msg string, ofs ...OptionFunc) (err error) {
+	options := make([]OptionFunc, 0, len(ofs)+1)
+
+	options = append(options, WithSafeMessage())
+	options = append(options, ofs...)
+
+	w := wrap(cause, msg, options)
+	if w == nil {
+		return
+	}
+
+	err = w
+
+	return
+}
+
+// Redact returns a copy of err with every sensitive part replaced by RedactedPlaceholder,
+// keeping types, stack traces and the structure of the error tree, so it can be returned
+// to users or formatted as usual:
+//
+//   - Messages are replaced unless marked safe (see NewSafe, WrapSafe and WithSafeMessage).
+//   - Field values are replaced unless marked safe (see WithSafeField), and always if their
+//     key matches DefaultSensitiveFields.
+//   - External errors, whose messages cannot be marked safe, are replaced by errors with
+//     the placeholder as message, keeping their Go type name and what they wrap.
+//
+// The copy does not match the original with Is on message, only on type.
+//
+// Parameters:
+//   - err (error): the error to redact
+//
+// Returns:
+//   - redacted (error): the redacted copy, or nil if err is nil
+func Redact(err error) (redacted error) {
+	redacted = redact(err, DefaultSensitiveFields)
+
+	return
+}
+
+// redact is the internal recursive implementation of Redact.
+//
+// Parameters:
+//   - err (error): the error to redact
+//   - sensitive (*regexp.Regexp): the pattern of field keys redacted even if marked safe, or nil
+//
+// Returns:
+//   - redacted (error): the redacted copy, or nil if err is nil
+func redact(err error, sensitive *regexp.Regexp) (redacted error) {
+	switch e := err.(type) {
+	case nil:
+	case *root:
+		redacted = &root{
+			isGlobal:    e.isGlobal,
+			isFormatted: e.isFormatted,
+			isRemote:    e.isRemote,
+			errType:     e.Type(),
+			message:     redactMessage(e.message, e.safe),
+			fields:      redactFields(e.Fields(), e.safe, sensitive),
+			cause:       redact(e.cause, sensitive),
+			trace:       e.trace,
+			frames:      e.frames,
+			safe:        e.safe,
+		}
+	case *wrapped:
+		redacted = &wrapped{
+			isFormatted: e.isFormatted,
+			isRemote:    e.isRemote,
+			errType:     e.Type(),
+			message:     redactMessage(e.message, e.safe),
+			fields:      redactFields(e.Fields(), e.safe, sensitive),
+			cause:       redact(e.cause, sensitive),
+			frame:       e.frame,
+			trace:       e.trace,
+			frames:      e.frames,
+			safe:        e.safe,
+		}
+	case *joined:
+		j := &joined{
+			isGlobal: e.isGlobal,
+			isRemote: e.isRemote,
+			errType:  e.Type(),
+			fields:   redactFields(e.Fields(), e.safe, sensitive),
+			errors:   make([]error, 0, len(e.errors)),
+			trace:    e.trace,
+			frames:   e.frames,
+			safe:     e.safe,
+		}
+
+		if e.message != "" {
+			j.message = redactMessage(e.message, e.safe)
+		}
+
+		for _, x := range e.errors {
+			j.errors = append(j.errors, redact(x, sensitive))
+		}
+
+		redacted = j
+	case interface{ Unwrap() []error }:
+		j := &joined{}
+
+		for _, x := range e.Unwrap() {
+			if x != nil {
+				j.errors = append(j.errors, redact(x, sensitive))
+			}
+		}
+
+		redacted = j
+	default:
+		redacted = &externalError{
+			message: RedactedPlaceholder,
+			goType:  goType(err),
+			cause:   redact(Unwrap(err), sensitive),
+		}
+	}
+
+	return
+}
+
+// redactMessage returns message, or RedactedPlaceholder if it is not marked safe.
+//
+// Parameters:
+//   - message (string): the message to redact
+//   - marks (safety): the safety marks of the error holding the message
+//
+// Returns:
+//   - redacted (string): the message or the placeholder
+func redactMessage(message string, marks safety) (redacted string) {
+	redacted = RedactedPlaceholder
+
+	if marks.message {
+		redacted = message
+	}
+
+	return
+}
+
+// redactFields replaces in fields, in place, the values that are not marked safe and
+// those whose key matches sensitive.
+//
+// Parameters:
+//   - fields (map[string]any): the fields to redact, modified in place
+//   - marks (safety): the safety marks of the error holding the fields
+//   - sensitive (*regexp.Regexp): the pattern of field keys redacted even if marked safe, or nil
+//
+// Returns:
+//   - redacted (map[string]any): fields, with the unsafe values replaced
+func redactFields(fields map[string]any, marks safety, sensitive *regexp.Regexp) (redacted map[string]any) {
+	for k := range fields {
+		_, safe := marks.fields[k]
+
+		if !safe || isSensitiveField(k, sensitive) {
+			fields[k] = RedactedPlaceholder
+		}
+	}
+
+	redacted = fields
+
+	return
+}
+
+// isSensitiveField reports whether a field key matches the sensitive field pattern.
+//
+// Parameters:
+//   - key (string): the field key
+//   - sensitive (*regexp.Regexp): the pattern of sensitive field keys, or nil
+//
+// Returns:
+//   - isSensitive (bool): true if key matches sensitive
+func isSensitiveField(key string, sensitive *regexp.Regexp) (isSensitive bool) {
+	isSensitive = sensitive != nil && sensitive.MatchString(key)
+
+	return
+}
@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NoError(t, Redact(nil))
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("SELECT * FROM users WHERE id = 42")
+		err = WrapSafe(err, "loading user")
+		err = Wrap(err, "reading /etc/app/config.yaml")
+
+		redacted := Redact(err)
+
+		require.Error(t, redacted)
+		assert.Equal(t, "[REDACTED]: loading user: [REDACTED]", redacted.Error())
+		assert.Equal(t, "reading /etc/app/config.yaml: loading user: SELECT * FROM users WHERE id = 42", err.Error())
+	})
+
+	t.Run("safe messages", func(t *testing.T) {
+		t.Parallel()
+
+		err := NewSafe("user not found", WithType("NOT_FOUND"))
+
+		redacted := Redact(Wrap(err, "handler", WithSafeMessage()))
+
+		assert.Equal(t, "handler: user not found", redacted.Error())
+		assert.ErrorIs(t, redacted, TypeMatcher("NOT_FOUND"))
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("failed",
+			WithField("query", "SELECT 1"),
+			WithSafeField("user_id", 42),
+			WithSafeField("api_token", "abc"),
+		)
+
+		redacted := Redact(err)
+
+		assert.Equal(t, map[string]any{"query": RedactedPlaceholder, "user_id": 42, "api_token": RedactedPlaceholder}, redacted.(*root).Fields())
+		assert.Equal(t, map[string]any{"query": "SELECT 1", "user_id": 42, "api_token": "abc"}, err.(*root).Fields())
+	})
+
+	t.Run("structure", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("dial tcp 10.0.0.1:5432: connection refused")
+
+		err := Wrap(fmt.Errorf("connecting to secret-host: %w", Wrap(cause, "db", WithType("DB"))), "handler")
+		err = JoinWith([]error{err, NewSafe("invalid input")}, WithSafeMessage(), WithType("BATCH"))
+
+		redacted := Redact(err)
+
+		original := Unpack(err)
+		unpacked := Unpack(redacted)
+
+		require.Len(t, unpacked.ErrJoinedTrees, 2)
+
+		tree := unpacked.ErrJoinedTrees[0]
+
+		require.Len(t, tree.ErrChain, 2)
+		assert.Equal(t, RedactedPlaceholder, tree.ErrChain[1].Message)
+		assert.Equal(t, "*fmt.wrapError", goType(tree.ErrChain[1].External))
+		assert.Equal(t, Type("DB"), tree.ErrRoot.Type)
+		assert.Equal(t, original.ErrJoinedTrees[0].ErrRoot.Stack, tree.ErrRoot.Stack)
+		assert.Equal(t, RedactedPlaceholder, tree.ErrExternal.Error())
+		assert.Equal(t, "*errors.errorString", goType(tree.ErrExternal))
+		assert.Equal(t, "invalid input", unpacked.ErrJoinedTrees[1].ErrRoot.Message)
+		assert.Equal(t, Type("BATCH"), redacted.(*joined).Type())
+		assert.NotContains(t, ToString(redacted, FormatWithTrace()), "secret-host")
+		assert.NotContains(t, ToString(redacted, FormatWithTrace()), "10.0.0.1")
+	})
+}
+
+func TestFormatter_Redact(t *testing.T) {
+	t.Parallel()
+
+	err := Wrap(New("SELECT 1", WithField("query", "SELECT 1"), WithField("password", "hunter2")), "loading", WithSafeMessage(), WithSafeField("user_id", 42))
+
+	t.Run("sensitive fields by default", func(t *testing.T) {
+		t.Parallel()
+
+		formatted := ToString(err)
+
+		assert.Contains(t, formatted, "query: SELECT 1")
+		assert.Contains(t, formatted, "password: [REDACTED]")
+		assert.NotContains(t, formatted, "hunter2")
+
+		assert.Equal(t, "hunter2", Cause(err).(*root).Fields()["password"])
+	})
+
+	t.Run("custom sensitive fields", func(t *testing.T) {
+		t.Parallel()
+
+		formatted := ToJSON(err, FormatSensitiveFields(regexp.MustCompile(`^query$`)))
+
+		rootPart := formatted["root"].(map[string]any)
+
+		assert.Equal(t, map[string]any{"query": RedactedPlaceholder, "password": "hunter2"}, rootPart["fields"])
+
+		formatted = ToJSON(err, FormatSensitiveFields(nil))
+
+		assert.Equal(t, "hunter2", formatted["root"].(map[string]any)["fields"].(map[string]any)["password"])
+	})
+
+	t.Run("redacted mode", func(t *testing.T) {
+		t.Parallel()
+
+		formatted := ToJSON(err, FormatRedacted())
+
+		rootPart := formatted["root"].(map[string]any)
+		chain := formatted["chain"].([]map[string]any)
+
+		assert.Equal(t, RedactedPlaceholder, rootPart["message"])
+		assert.Equal(t, map[string]any{"query": RedactedPlaceholder, "password": RedactedPlaceholder}, rootPart["fields"])
+		assert.Equal(t, "loading", chain[0]["message"])
+		assert.Equal(t, map[string]any{"user_id": 42}, chain[0]["fields"])
+
+		assert.NotContains(t, ToString(err, FormatRedacted()), "SELECT 1")
+	})
+
+	t.Run("redacted log value", func(t *testing.T) {
+		t.Parallel()
+
+		value := NewFormatter(FormatRedacted()).LogValue(err)
+
+		assert.Equal(t, "loading: [REDACTED]", value.Group()[0].Value.String())
+		assert.Equal(t, slog.KindGroup, value.Kind())
+	})
+}
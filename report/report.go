@@ -0,0 +1,697 @@
+// Package report sends errors to collectors asynchronously.
+//
+// A Reporter accepts errors from any goroutine without blocking. The AsyncReporter
+// implementation queues them in a bounded queue, rate-limits and deduplicates them,
+// serializes them with hqgoerrors.ToJSON, and hands them in batches to a Sink, such as
+// a WriterSink (JSON Lines), a rotating FileSink, an HTTPSink or a SentrySink.
+package report
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+// Reporter is the interface of error reporters.
+type Reporter interface {
+	// Report submits an error for reporting. It must not block.
+	Report(err error)
+	// Flush sends the errors submitted so far, waiting until they are sent or ctx is done.
+	Flush(ctx context.Context) (err error)
+	// Close flushes the submitted errors and releases the reporter's resources.
+	Close() (err error)
+}
+
+// Entry is a reported error, as passed to sinks.
+//
+// Fields:
+//   - Time (time.Time): when the error was first reported
+//   - Fingerprint (string): the error's fingerprint (see hqgoerrors.Fingerprint)
+//   - Count (int): the number of reports of errors with this fingerprint merged into the entry
+//   - Error (map[string]any): the error, as formatted by hqgoerrors.ToJSON
+type Entry struct {
+	Time        time.Time      `json:"time"`
+	Fingerprint string         `json:"fingerprint"`
+	Count       int            `json:"count"`
+	Error       map[string]any `json:"error"`
+}
+
+// Sink is the interface of the destinations of an AsyncReporter.
+// Sinks are called from a single goroutine. A sink implementing io.Closer is closed
+// when the reporter is closed.
+type Sink interface {
+	// Send delivers a batch of entries. Errors marked retryable (see hqgoerrors.IsRetryable)
+	// are retried according to the reporter's retry policy.
+	Send(ctx context.Context, entries []Entry) (err error)
+}
+
+// DropPolicy chooses which error an AsyncReporter drops when its queue is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the error being reported, keeping the queued ones.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest queued error to make room for the one being reported.
+	DropOldest
+)
+
+// Options holds configuration for an AsyncReporter.
+//
+// Fields:
+//   - QueueSize (int): maximum number of errors waiting to be processed (default: 1024)
+//   - BatchSize (int): maximum number of entries sent to the sink at once (default: 100)
+//   - FlushInterval (time.Duration): maximum time an entry waits before being sent (default: 5s)
+//   - Timeout (time.Duration): maximum duration of a send, including retries (default: 10s)
+//   - RateLimit (float64): maximum number of errors accepted per second, 0 for no limit (default: 0)
+//   - Burst (int): number of errors accepted at once above the rate limit (default: 1)
+//   - DropPolicy (DropPolicy): which error to drop when the queue is full (default: DropNewest)
+//   - Deduplicate (bool): merge errors with the same fingerprint within a batch (default: true)
+//   - RetryPolicy (hqgoerrors.RetryPolicy): retry policy of failed sends (default: the zero policy)
+//   - FormatterOptions ([]hqgoerrors.FormatterOptionFunc): options of the JSON serialization
+//     (default: with stack traces)
+//   - ErrorHandler (func(err error)): called with the errors of failed sends (default: none)
+type Options struct {
+	QueueSize        int
+	BatchSize        int
+	FlushInterval    time.Duration
+	Timeout          time.Duration
+	RateLimit        float64
+	Burst            int
+	DropPolicy       DropPolicy
+	Deduplicate      bool
+	RetryPolicy      hqgoerrors.RetryPolicy
+	FormatterOptions []hqgoerrors.FormatterOptionFunc
+	ErrorHandler     func(err error)
+}
+
+// OptionFunc is a function type for configuring Options.
+// Used with NewAsyncReporter to set custom options.
+type OptionFunc func(options *Options)
+
+// Stats holds the counters of an AsyncReporter.
+//
+// Fields:
+//   - Reported (uint64): errors accepted in the queue
+//   - Dropped (uint64): errors dropped because the queue was full or the reporter closed,
+//     including queued errors dropped by DropOldest
+//   - RateLimited (uint64): errors dropped by the rate limit
+//   - Sent (uint64): entries delivered to the sink
+//   - Failed (uint64): entries the sink failed to deliver
+type Stats struct {
+	Reported    uint64
+	Dropped     uint64
+	RateLimited uint64
+	Sent        uint64
+	Failed      uint64
+}
+
+// report is an error waiting in the queue of an AsyncReporter.
+//
+// Fields:
+//   - err (error): the reported error
+//   - time (time.Time): when it was reported
+type report struct {
+	err  error
+	time time.Time
+}
+
+// AsyncReporter is a Reporter processing errors in a background goroutine.
+// It is safe for concurrent use.
+//
+// Fields:
+//   - sink (Sink): where entries are sent
+//   - options (*Options): the configuration options
+//   - formatter (*hqgoerrors.Formatter): the formatter serializing errors
+//   - limiter (*limiter): the rate limiter, or nil without rate limit
+//   - queue (chan report): the bounded queue of reported errors
+//   - flushes (chan flushRequest): flush requests for the background goroutine
+//   - closing (chan struct{}): closed when Close is called
+//   - done (chan struct{}): closed when the background goroutine returns
+//   - closeOnce (sync.Once): makes Close idempotent
+//   - mu (sync.RWMutex): guards closed, so that no error is enqueued once Close drains the queue
+//   - closed (bool): whether Close was called
+//   - closeErr (error): the result of Close
+//   - pending ([]Entry): the entries of the next batch, owned by the background goroutine
+//   - index (map[string]int): the position in pending of each fingerprint, when deduplicating
+//   - reported, dropped, rateLimited, sent, failed (atomic.Uint64): the Stats counters
+type AsyncReporter struct {
+	sink      Sink
+	options   *Options
+	formatter *hqgoerrors.Formatter
+	limiter   *limiter
+	queue     chan report
+	flushes   chan flushRequest
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	closeErr  error
+	pending   []Entry
+	index     map[string]int
+
+	reported    atomic.Uint64
+	dropped     atomic.Uint64
+	rateLimited atomic.Uint64
+	sent        atomic.Uint64
+	failed      atomic.Uint64
+}
+
+// flushRequest asks the background goroutine of an AsyncReporter to send its entries.
+//
+// Fields:
+//   - ctx (context.Context): the context of the Flush call
+//   - result (chan error): receives the result of the send
+type flushRequest struct {
+	ctx    context.Context
+	result chan error
+}
+
+var _ Reporter = (*AsyncReporter)(nil)
+
+// Report submits an error for reporting without blocking. The error is dropped if it
+// exceeds the rate limit, if the queue is full (see DropPolicy), or if the reporter is
+// closed. Nil errors are ignored.
+//
+// Parameters:
+//   - err (error): the error to report
+func (r *AsyncReporter) Report(err error) {
+	if err == nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		r.dropped.Add(1)
+
+		return
+	}
+
+	now := time.Now()
+
+	if r.limiter != nil && !r.limiter.allow(now) {
+		r.rateLimited.Add(1)
+
+		return
+	}
+
+	item := report{err: err, time: now}
+
+	for {
+		select {
+		case r.queue <- item:
+			r.reported.Add(1)
+
+			return
+		default:
+		}
+
+		if r.options.DropPolicy != DropOldest {
+			r.dropped.Add(1)
+
+			return
+		}
+
+		select {
+		case <-r.queue:
+			r.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// Flush sends the errors reported so far, waiting until the sink returns or ctx is done.
+//
+// Parameters:
+//   - ctx (context.Context): the context bounding the wait and the send
+//
+// Returns:
+//   - err (error): the error of the send, or the reason ctx is done
+func (r *AsyncReporter) Flush(ctx context.Context) (err error) {
+	request := flushRequest{ctx: ctx, result: make(chan error, 1)}
+
+	select {
+	case r.flushes <- request:
+	case <-r.done:
+		return
+	case <-ctx.Done():
+		err = hqgoerrors.FromContext(ctx)
+
+		return
+	}
+
+	select {
+	case err = <-request.result:
+	case <-ctx.Done():
+		err = hqgoerrors.FromContext(ctx)
+	}
+
+	return
+}
+
+// Close stops accepting errors, sends the queued ones, stops the background goroutine
+// and closes the sink if it implements io.Closer. Calling it more than once returns the
+// result of the first call.
+//
+// Returns:
+//   - err (error): the errors of the last send and of closing the sink, joined
+func (r *AsyncReporter) Close() (err error) {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+
+		r.closed = true
+
+		r.mu.Unlock()
+
+		close(r.closing)
+
+		<-r.done
+
+		if closer, ok := r.sink.(io.Closer); ok {
+			r.closeErr = hqgoerrors.Join(r.closeErr, closer.Close())
+		}
+	})
+
+	err = r.closeErr
+
+	return
+}
+
+// Stats returns a snapshot of the reporter's counters.
+//
+// Returns:
+//   - stats (Stats): the counters
+func (r *AsyncReporter) Stats() (stats Stats) {
+	stats = Stats{
+		Reported:    r.reported.Load(),
+		Dropped:     r.dropped.Load(),
+		RateLimited: r.rateLimited.Load(),
+		Sent:        r.sent.Load(),
+		Failed:      r.failed.Load(),
+	}
+
+	return
+}
+
+// run is the background goroutine of the reporter. It collects the queued errors into
+// the pending batch, and sends it when full, at every flush interval, on Flush and on Close.
+func (r *AsyncReporter) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.options.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item := <-r.queue:
+			r.add(item)
+
+			if len(r.pending) >= r.options.BatchSize {
+				r.send(context.Background())
+			}
+		case <-ticker.C:
+			r.send(context.Background())
+		case request := <-r.flushes:
+			r.drain()
+
+			request.result <- r.send(request.ctx)
+		case <-r.closing:
+			r.drain()
+
+			r.closeErr = r.send(context.Background())
+
+			return
+		}
+	}
+}
+
+// drain moves the queued errors into pending batches, sending the full ones.
+func (r *AsyncReporter) drain() {
+	for {
+		select {
+		case item := <-r.queue:
+			r.add(item)
+
+			if len(r.pending) >= r.options.BatchSize {
+				r.send(context.Background())
+			}
+		default:
+			return
+		}
+	}
+}
+
+// add adds a queued error to the pending batch, merging it into the entry with the same
+// fingerprint when deduplicating.
+//
+// Parameters:
+//   - item (report): the queued error
+func (r *AsyncReporter) add(item report) {
+	fingerprint := hqgoerrors.Fingerprint(item.err)
+
+	if r.options.Deduplicate {
+		if i, ok := r.index[fingerprint]; ok {
+			r.pending[i].Count++
+
+			return
+		}
+
+		r.index[fingerprint] = len(r.pending)
+	}
+
+	r.pending = append(r.pending, Entry{
+		Time:        item.time,
+		Fingerprint: fingerprint,
+		Count:       1,
+		Error:       r.formatter.JSON(item.err),
+	})
+}
+
+// send sends the pending batch to the sink, retrying according to the retry policy,
+// and starts a new batch whatever the outcome.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the send, bounded by the Timeout option
+//
+// Returns:
+//   - err (error): the error of the sink, if the send failed
+func (r *AsyncReporter) send(ctx context.Context) (err error) {
+	if len(r.pending) == 0 {
+		return
+	}
+
+	batch := r.pending
+
+	r.pending = nil
+
+	clear(r.index)
+
+	ctx, cancel := context.WithTimeout(ctx, r.options.Timeout)
+	defer cancel()
+
+	err = hqgoerrors.Retry(ctx, r.options.RetryPolicy, func() error {
+		return r.sink.Send(ctx, batch)
+	})
+	if err != nil {
+		r.failed.Add(uint64(len(batch)))
+
+		if r.options.ErrorHandler != nil {
+			r.options.ErrorHandler(err)
+		}
+
+		return
+	}
+
+	r.sent.Add(uint64(len(batch)))
+
+	return
+}
+
+// NewAsyncReporter creates an AsyncReporter sending to sink, and starts its background
+// goroutine. It must be closed with Close.
+//
+// Parameters:
+//   - sink (Sink): where entries are sent
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - reporter (*AsyncReporter): the new reporter instance
+func NewAsyncReporter(sink Sink, ofs ...OptionFunc) (reporter *AsyncReporter) {
+	options := newOptions(ofs...)
+
+	reporter = &AsyncReporter{
+		sink:      sink,
+		options:   options,
+		formatter: hqgoerrors.NewFormatter(options.FormatterOptions...),
+		queue:     make(chan report, options.QueueSize),
+		flushes:   make(chan flushRequest),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+		index:     map[string]int{},
+	}
+
+	if options.RateLimit > 0 {
+		reporter.limiter = newLimiter(options.RateLimit, options.Burst)
+	}
+
+	go reporter.run()
+
+	return
+}
+
+// WithQueueSize returns an option function setting the maximum number of errors waiting
+// to be processed.
+//
+// Parameters:
+//   - size (int): the queue size
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithQueueSize(size int) (f OptionFunc) {
+	return func(options *Options) {
+		options.QueueSize = size
+	}
+}
+
+// WithBatchSize returns an option function setting the maximum number of entries sent at once.
+//
+// Parameters:
+//   - size (int): the batch size
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithBatchSize(size int) (f OptionFunc) {
+	return func(options *Options) {
+		options.BatchSize = size
+	}
+}
+
+// WithFlushInterval returns an option function setting the maximum time an entry waits
+// before being sent.
+//
+// Parameters:
+//   - interval (time.Duration): the flush interval
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithFlushInterval(interval time.Duration) (f OptionFunc) {
+	return func(options *Options) {
+		options.FlushInterval = interval
+	}
+}
+
+// WithTimeout returns an option function setting the maximum duration of a send,
+// including retries.
+//
+// Parameters:
+//   - timeout (time.Duration): the timeout
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithTimeout(timeout time.Duration) (f OptionFunc) {
+	return func(options *Options) {
+		options.Timeout = timeout
+	}
+}
+
+// WithRateLimit returns an option function limiting the number of errors accepted per
+// second, with a token bucket allowing bursts of burst errors.
+//
+// Parameters:
+//   - perSecond (float64): the sustained rate
+//   - burst (int): the bucket size
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithRateLimit(perSecond float64, burst int) (f OptionFunc) {
+	return func(options *Options) {
+		options.RateLimit = perSecond
+		options.Burst = burst
+	}
+}
+
+// WithDropPolicy returns an option function setting which error to drop when the queue is full.
+//
+// Parameters:
+//   - policy (DropPolicy): the drop policy
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithDropPolicy(policy DropPolicy) (f OptionFunc) {
+	return func(options *Options) {
+		options.DropPolicy = policy
+	}
+}
+
+// WithDeduplicate returns an option function enabling or disabling the merging of errors
+// with the same fingerprint within a batch.
+//
+// Parameters:
+//   - deduplicate (bool): whether to deduplicate
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithDeduplicate(deduplicate bool) (f OptionFunc) {
+	return func(options *Options) {
+		options.Deduplicate = deduplicate
+	}
+}
+
+// WithRetryPolicy returns an option function setting the retry policy of failed sends.
+//
+// Parameters:
+//   - policy (hqgoerrors.RetryPolicy): the retry policy
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithRetryPolicy(policy hqgoerrors.RetryPolicy) (f OptionFunc) {
+	return func(options *Options) {
+		options.RetryPolicy = policy
+	}
+}
+
+// WithFormatterOptions returns an option function setting the options of the JSON
+// serialization of errors, e.g. hqgoerrors.FormatRedacted().
+//
+// Parameters:
+//   - ofs (...hqgoerrors.FormatterOptionFunc): the formatter options
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithFormatterOptions(ofs ...hqgoerrors.FormatterOptionFunc) (f OptionFunc) {
+	return func(options *Options) {
+		options.FormatterOptions = ofs
+	}
+}
+
+// WithErrorHandler returns an option function setting the function called with the
+// errors of failed sends.
+//
+// Parameters:
+//   - handler (func(err error)): the error handler
+//
+// Returns:
+//   - f (OptionFunc): the option function
+func WithErrorHandler(handler func(err error)) (f OptionFunc) {
+	return func(options *Options) {
+		options.ErrorHandler = handler
+	}
+}
+
+// Default values of Options fields.
+const (
+	defaultQueueSize     = 1024
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5 * time.Second
+	defaultTimeout       = 10 * time.Second
+)
+
+// newOptions creates Options with the defaults and applies the option functions.
+// Invalid sizes and durations are replaced by the defaults.
+//
+// Parameters:
+//   - ofs (...OptionFunc): variadic option functions
+//
+// Returns:
+//   - options (*Options): the configured options
+func newOptions(ofs ...OptionFunc) (options *Options) {
+	options = &Options{
+		QueueSize:        defaultQueueSize,
+		BatchSize:        defaultBatchSize,
+		FlushInterval:    defaultFlushInterval,
+		Timeout:          defaultTimeout,
+		Burst:            1,
+		Deduplicate:      true,
+		FormatterOptions: []hqgoerrors.FormatterOptionFunc{hqgoerrors.FormatWithTrace()},
+	}
+
+	for _, f := range ofs {
+		f(options)
+	}
+
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultQueueSize
+	}
+
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultBatchSize
+	}
+
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = defaultFlushInterval
+	}
+
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+
+	options.Burst = max(options.Burst, 1)
+
+	return
+}
+
+// limiter is a token bucket rate limiter.
+//
+// Fields:
+//   - mu (sync.Mutex): guards tokens and last
+//   - rate (float64): tokens added per second
+//   - burst (float64): maximum number of tokens
+//   - tokens (float64): tokens available
+//   - last (time.Time): when tokens was last updated
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token if one is available.
+//
+// Parameters:
+//   - now (time.Time): the current time
+//
+// Returns:
+//   - allowed (bool): true if a token was taken
+func (l *limiter) allow(now time.Time) (allowed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+
+		allowed = true
+	}
+
+	return
+}
+
+// newLimiter creates a full token bucket.
+//
+// Parameters:
+//   - rate (float64): tokens added per second
+//   - burst (int): maximum number of tokens
+//
+// Returns:
+//   - l (*limiter): the new limiter
+func newLimiter(rate float64, burst int) (l *limiter) {
+	l = &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+
+	return
+}
@@ -0,0 +1,371 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink records the batches it receives. Each send first calls hook, if set.
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Entry
+	hook    func() error
+	closed  bool
+}
+
+func (s *memorySink) Send(_ context.Context, entries []Entry) (err error) {
+	if s.hook != nil {
+		if err = s.hook(); err != nil {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.batches = append(s.batches, entries)
+
+	return
+}
+
+func (s *memorySink) Close() (err error) {
+	s.closed = true
+
+	return
+}
+
+func (s *memorySink) sizes() (sizes []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, batch := range s.batches {
+		sizes = append(sizes, len(batch))
+	}
+
+	return
+}
+
+func (s *memorySink) messages() (messages []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, batch := range s.batches {
+		for _, entry := range batch {
+			part, ok := entry.Error["root"].(map[string]any)
+			if !ok {
+				part = entry.Error["external"].(map[string]any)
+			}
+
+			messages = append(messages, part["message"].(string))
+		}
+	}
+
+	return
+}
+
+func TestAsyncReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deduplicated batch", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithFlushInterval(time.Hour))
+
+		defer reporter.Close()
+
+		for i := range 3 {
+			reporter.Report(hqgoerrors.Newf("order %d not found", i, hqgoerrors.WithType("NOT_FOUND")))
+		}
+
+		reporter.Report(errors.New("other"))
+		reporter.Report(nil)
+
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		require.Equal(t, []int{2}, sink.sizes())
+
+		entry := sink.batches[0][0]
+
+		assert.Equal(t, 3, entry.Count)
+		assert.Len(t, entry.Fingerprint, 32)
+		assert.Equal(t, "NOT_FOUND", entry.Error["root"].(map[string]any)["type"])
+		assert.Contains(t, entry.Error["root"].(map[string]any), "stack")
+		assert.False(t, entry.Time.IsZero())
+		assert.Equal(t, 1, sink.batches[0][1].Count)
+
+		assert.Equal(t, Stats{Reported: 4, Sent: 2}, reporter.Stats())
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithBatchSize(2), WithDeduplicate(false), WithFlushInterval(time.Hour))
+
+		defer reporter.Close()
+
+		for i := range 5 {
+			reporter.Report(fmt.Errorf("error %d", i))
+		}
+
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		assert.Equal(t, []int{2, 2, 1}, sink.sizes())
+	})
+
+	t.Run("flush interval", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithFlushInterval(10*time.Millisecond))
+
+		defer reporter.Close()
+
+		reporter.Report(errors.New("failed"))
+
+		assert.Eventually(t, func() bool { return len(sink.sizes()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("formatter options", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithFormatterOptions(hqgoerrors.FormatRedacted()))
+
+		defer reporter.Close()
+
+		reporter.Report(hqgoerrors.New("SELECT 1"))
+
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		assert.Equal(t, []string{hqgoerrors.RedactedPlaceholder}, sink.messages())
+		assert.NotContains(t, sink.batches[0][0].Error["root"].(map[string]any), "stack")
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithRateLimit(0.001, 2), WithDeduplicate(false))
+
+		defer reporter.Close()
+
+		for i := range 5 {
+			reporter.Report(fmt.Errorf("error %d", i))
+		}
+
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		assert.Equal(t, []string{"error 0", "error 1"}, sink.messages())
+		assert.Equal(t, Stats{Reported: 2, RateLimited: 3, Sent: 2}, reporter.Stats())
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		sink := &memorySink{hook: func() error {
+			calls++
+
+			if calls == 1 {
+				return hqgoerrors.New("unavailable", hqgoerrors.WithRetryable(true))
+			}
+
+			return nil
+		}}
+
+		reporter := NewAsyncReporter(sink, WithRetryPolicy(hqgoerrors.RetryPolicy{InitialDelay: time.Millisecond}))
+
+		defer reporter.Close()
+
+		reporter.Report(errors.New("failed"))
+
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, []string{"failed"}, sink.messages())
+	})
+
+	t.Run("sink failure", func(t *testing.T) {
+		t.Parallel()
+
+		sinkErr := errors.New("unreachable")
+
+		var handled []error
+
+		sink := &memorySink{hook: func() error { return sinkErr }}
+
+		reporter := NewAsyncReporter(sink, WithErrorHandler(func(err error) { handled = append(handled, err) }))
+
+		defer reporter.Close()
+
+		reporter.Report(errors.New("failed"))
+
+		err := reporter.Flush(t.Context())
+
+		require.ErrorIs(t, err, sinkErr)
+		require.Len(t, handled, 1)
+		assert.ErrorIs(t, handled[0], sinkErr)
+		assert.Equal(t, Stats{Reported: 1, Failed: 1}, reporter.Stats())
+	})
+
+	t.Run("flush canceled", func(t *testing.T) {
+		t.Parallel()
+
+		release := make(chan struct{})
+
+		sink := &memorySink{hook: func() error {
+			<-release
+
+			return nil
+		}}
+
+		reporter := NewAsyncReporter(sink)
+
+		defer reporter.Close()
+		defer close(release)
+
+		reporter.Report(errors.New("failed"))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		err := reporter.Flush(ctx)
+
+		assert.True(t, hqgoerrors.IsDeadlineExceeded(err))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		t.Parallel()
+
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithFlushInterval(time.Hour))
+
+		reporter.Report(errors.New("failed"))
+
+		require.NoError(t, reporter.Close())
+		require.NoError(t, reporter.Close())
+		require.NoError(t, reporter.Flush(t.Context()))
+
+		reporter.Report(errors.New("too late"))
+
+		assert.Equal(t, []string{"failed"}, sink.messages())
+		assert.True(t, sink.closed)
+		assert.Equal(t, Stats{Reported: 1, Dropped: 1, Sent: 1}, reporter.Stats())
+	})
+}
+
+func TestAsyncReporter_ConcurrentClose(t *testing.T) {
+	t.Parallel()
+
+	for range 20 {
+		sink := &memorySink{}
+
+		reporter := NewAsyncReporter(sink, WithDeduplicate(false), WithQueueSize(10000))
+
+		var wg sync.WaitGroup
+
+		for i := range 4 {
+			wg.Go(func() {
+				for j := range 100 {
+					reporter.Report(fmt.Errorf("error %d-%d", i, j))
+				}
+			})
+		}
+
+		require.NoError(t, reporter.Close())
+
+		wg.Wait()
+
+		stats := reporter.Stats()
+
+		assert.Equal(t, uint64(400), stats.Reported+stats.Dropped)
+		assert.Equal(t, stats.Reported, stats.Sent)
+		assert.Len(t, sink.messages(), int(stats.Sent))
+	}
+}
+
+func TestAsyncReporter_DropPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		policy   DropPolicy
+		expected []string
+	}{
+		{name: "drop newest", policy: DropNewest, expected: []string{"error 0", "error 1", "error 2"}},
+		{name: "drop oldest", policy: DropOldest, expected: []string{"error 0", "error 2", "error 3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sending := make(chan struct{}, 1)
+			release := make(chan struct{})
+
+			sink := &memorySink{hook: func() error {
+				select {
+				case sending <- struct{}{}:
+					<-release
+				default:
+				}
+
+				return nil
+			}}
+
+			reporter := NewAsyncReporter(sink, WithQueueSize(2), WithBatchSize(1), WithDeduplicate(false), WithDropPolicy(tt.policy))
+
+			defer reporter.Close()
+
+			reporter.Report(errors.New("error 0"))
+
+			<-sending
+
+			for i := 1; i <= 3; i++ {
+				reporter.Report(fmt.Errorf("error %d", i))
+			}
+
+			close(release)
+
+			require.NoError(t, reporter.Flush(t.Context()))
+
+			assert.Equal(t, tt.expected, sink.messages())
+			assert.Equal(t, uint64(1), reporter.Stats().Dropped)
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	l := newLimiter(2, 2)
+
+	now := l.last
+
+	assert.True(t, l.allow(now))
+	assert.True(t, l.allow(now))
+	assert.False(t, l.allow(now))
+	assert.False(t, l.allow(now.Add(-time.Second)))
+	assert.True(t, l.allow(now.Add(500*time.Millisecond)))
+	assert.False(t, l.allow(now.Add(500*time.Millisecond)))
+	assert.True(t, l.allow(now.Add(time.Hour)))
+	assert.True(t, l.allow(now.Add(time.Hour)))
+	assert.False(t, l.allow(now.Add(time.Hour)))
+}
@@ -0,0 +1,239 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+// sentryClient identifies the sender in the authentication header of Sentry requests.
+const sentryClient = "hq-go-errors"
+
+// SentrySink is a Sink sending entries to Sentry, or a compatible service, as events
+// (see hqgoerrors.ToSentryEvent) in the envelope format, one envelope per entry.
+// Entries are decoded with hqgoerrors.FromJSON, so the stack traces, types and fields
+// the reporter formatted survive; their fingerprint and time are kept.
+// Responses with a status of 429 or 5xx fail with a retryable error honoring the
+// Retry-After header. As a batch is retried whole, entries sent before a failure may be
+// sent again.
+//
+// Fields:
+//   - DSN (string): the DSN of the Sentry project
+//   - Client (*http.Client): the client sending the requests
+//   - Release (string): the release set on events that have none, if not empty
+//   - Environment (string): the environment set on events that have none, if not empty
+//   - endpoint (string): the envelope endpoint of the project, derived from the DSN
+//   - auth (string): the value of the X-Sentry-Auth header, derived from the DSN
+type SentrySink struct {
+	DSN         string
+	Client      *http.Client
+	Release     string
+	Environment string
+	endpoint    string
+	auth        string
+}
+
+var _ Sink = (*SentrySink)(nil)
+
+// Send sends each entry as an event, stopping at the first failure.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the requests
+//   - entries ([]Entry): the entries to send
+//
+// Returns:
+//   - err (error): the error of the first failed send, if any
+func (s *SentrySink) Send(ctx context.Context, entries []Entry) (err error) {
+	for _, entry := range entries {
+		event := hqgoerrors.ToSentryEvent(hqgoerrors.FromJSON(entry.Error))
+		if event == nil {
+			continue
+		}
+
+		event.Timestamp = entry.Time
+		event.Fingerprint = []string{entry.Fingerprint}
+
+		if entry.Count > 1 {
+			if event.Extra == nil {
+				event.Extra = map[string]any{}
+			}
+
+			event.Extra["count"] = entry.Count
+		}
+
+		if err = s.SendEvent(ctx, event); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// SendEvent sends an event in an envelope.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the request
+//   - event (*hqgoerrors.SentryEvent): the event to send
+//
+// Returns:
+//   - err (error): any error from encoding or sending, or an error holding the status
+//     in the "status" field if the response status is not 2xx
+func (s *SentrySink) SendEvent(ctx context.Context, event *hqgoerrors.SentryEvent) (err error) {
+	if event.Release == "" {
+		event.Release = s.Release
+	}
+
+	if event.Environment == "" {
+		event.Environment = s.Environment
+	}
+
+	body, err := s.envelope(event)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		err = hqgoerrors.Wrap(err, "sending Sentry event", hqgoerrors.WithField("url", s.endpoint))
+
+		return
+	}
+
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return
+	}
+
+	err = statusError("sending Sentry event", s.endpoint, res)
+
+	return
+}
+
+// envelope encodes an event as an envelope: the envelope header, the item header and the
+// event, each on its own line.
+//
+// Parameters:
+//   - event (*hqgoerrors.SentryEvent): the event to encode
+//
+// Returns:
+//   - body ([]byte): the envelope
+//   - err (error): any error from encoding
+func (s *SentrySink) envelope(event *hqgoerrors.SentryEvent) (body []byte, err error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	if err = encoder.Encode(map[string]any{
+		"event_id": event.EventID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+		"dsn":      s.DSN,
+	}); err != nil {
+		return
+	}
+
+	if err = encoder.Encode(map[string]any{
+		"type":         "event",
+		"length":       len(payload),
+		"content_type": "application/json",
+	}); err != nil {
+		return
+	}
+
+	buf.Write(payload)
+	buf.WriteByte('\n')
+
+	body = buf.Bytes()
+
+	return
+}
+
+// NewSentrySink creates a SentrySink sending to the project of dsn with http.DefaultClient.
+//
+// Parameters:
+//   - dsn (string): the DSN of the Sentry project (e.g. "https://<key>@o1.ingest.sentry.io/<project>")
+//
+// Returns:
+//   - sink (*SentrySink): the new sink instance
+//   - err (error): an error if the DSN is invalid
+func NewSentrySink(dsn string) (sink *SentrySink, err error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		err = hqgoerrors.Wrap(err, "invalid Sentry DSN")
+
+		return
+	}
+
+	prefix, project, _ := cutLast(strings.TrimSuffix(parsed.Path, "/"), "/")
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.User.Username() == "" || project == "" {
+		err = hqgoerrors.New("invalid Sentry DSN: expected scheme://key@host/project", hqgoerrors.WithField("dsn", dsn))
+
+		return
+	}
+
+	endpoint := url.URL{
+		Scheme: parsed.Scheme,
+		Host:   parsed.Host,
+		Path:   prefix + "/api/" + project + "/envelope/",
+	}
+
+	sink = &SentrySink{
+		DSN:      dsn,
+		Client:   http.DefaultClient,
+		endpoint: endpoint.String(),
+		auth:     "Sentry sentry_version=7, sentry_client=" + sentryClient + ", sentry_key=" + parsed.User.Username(),
+	}
+
+	return
+}
+
+// cutLast slices s around the last instance of sep.
+//
+// Parameters:
+//   - s (string): the string to slice
+//   - sep (string): the separator
+//
+// Returns:
+//   - before (string): the text before the last separator, or s if not found
+//   - after (string): the text after the last separator, or empty if not found
+//   - found (bool): true if sep appears in s
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		before = s
+
+		return
+	}
+
+	before, after, found = s[:i], s[i+len(sep):], true
+
+	return
+}
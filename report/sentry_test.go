@@ -0,0 +1,205 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentryServer is an httptest handler standing in for Sentry's envelope endpoint.
+// It answers with the queued statuses first, then with 200.
+type sentryServer struct {
+	mu        sync.Mutex
+	statuses  []int
+	paths     []string
+	headers   []http.Header
+	envelopes [][]map[string]any
+}
+
+func (s *sentryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.paths = append(s.paths, r.URL.Path)
+	s.headers = append(s.headers, r.Header.Clone())
+
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+
+		s.statuses = s.statuses[1:]
+
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+
+		return
+	}
+
+	var envelope []map[string]any
+
+	scanner := bufio.NewScanner(r.Body)
+
+	scanner.Buffer(nil, 1<<20)
+
+	for scanner.Scan() {
+		var line map[string]any
+
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		envelope = append(envelope, line)
+	}
+
+	s.envelopes = append(s.envelopes, envelope)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func newSentryTestSink(t *testing.T, handler http.Handler) (sink *SentrySink, dsn string) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+
+	t.Cleanup(server.Close)
+
+	dsn = strings.Replace(server.URL, "://", "://public@", 1) + "/prefix/42"
+
+	sink, err := NewSentrySink(dsn)
+
+	require.NoError(t, err)
+
+	return
+}
+
+func TestNewSentrySink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dsn      string
+		endpoint string
+	}{
+		{dsn: "https://key@o1.ingest.sentry.io/42", endpoint: "https://o1.ingest.sentry.io/api/42/envelope/"},
+		{dsn: "http://key@localhost:9000/sentry/7/", endpoint: "http://localhost:9000/sentry/api/7/envelope/"},
+		{dsn: "https://o1.ingest.sentry.io/42"},
+		{dsn: "https://key@o1.ingest.sentry.io"},
+		{dsn: "ftp://key@o1.ingest.sentry.io/42"},
+		{dsn: "://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dsn, func(t *testing.T) {
+			t.Parallel()
+
+			sink, err := NewSentrySink(tt.dsn)
+
+			if tt.endpoint == "" {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.endpoint, sink.endpoint)
+			assert.Contains(t, sink.auth, "sentry_key=key")
+		})
+	}
+}
+
+func TestSentrySink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("send event", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &sentryServer{}
+
+		sink, dsn := newSentryTestSink(t, handler)
+
+		sink.Release = "app@1.0.0"
+
+		event := hqgoerrors.ToSentryEvent(hqgoerrors.New("not found", hqgoerrors.WithType("NOT_FOUND")))
+
+		require.NoError(t, sink.SendEvent(t.Context(), event))
+
+		require.Len(t, handler.envelopes, 1)
+		assert.Equal(t, "/prefix/api/42/envelope/", handler.paths[0])
+		assert.Equal(t, "application/x-sentry-envelope", handler.headers[0].Get("Content-Type"))
+		assert.Contains(t, handler.headers[0].Get("X-Sentry-Auth"), "sentry_key=public")
+
+		envelope := handler.envelopes[0]
+
+		require.Len(t, envelope, 3)
+		assert.Equal(t, event.EventID, envelope[0]["event_id"])
+		assert.Equal(t, dsn, envelope[0]["dsn"])
+		assert.Equal(t, "event", envelope[1]["type"])
+		assert.Equal(t, event.EventID, envelope[2]["event_id"])
+		assert.Equal(t, "app@1.0.0", envelope[2]["release"])
+
+		exception := envelope[2]["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+
+		assert.Equal(t, "NOT_FOUND", exception["type"])
+		assert.Equal(t, "not found", exception["value"])
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			status    int
+			retryable bool
+		}{
+			{status: http.StatusTooManyRequests, retryable: true},
+			{status: http.StatusBadRequest, retryable: false},
+		}
+
+		for _, tt := range tests {
+			sink, _ := newSentryTestSink(t, &sentryServer{statuses: []int{tt.status}})
+
+			err := sink.SendEvent(t.Context(), hqgoerrors.ToSentryEvent(hqgoerrors.New("failed")))
+
+			require.Error(t, err)
+			assert.Equal(t, tt.retryable, hqgoerrors.IsRetryable(err))
+			assert.Equal(t, tt.status, hqgoerrors.MustField[int](err, "status"))
+		}
+	})
+
+	t.Run("reporter", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &sentryServer{statuses: []int{http.StatusServiceUnavailable}}
+
+		sink, _ := newSentryTestSink(t, handler)
+
+		reporter := NewAsyncReporter(sink, WithRetryPolicy(hqgoerrors.RetryPolicy{InitialDelay: time.Millisecond}))
+
+		for range 2 {
+			reporter.Report(hqgoerrors.Wrap(hqgoerrors.New("not found", hqgoerrors.WithType("NOT_FOUND")), "loading"))
+		}
+
+		require.NoError(t, reporter.Close())
+
+		require.Len(t, handler.envelopes, 1)
+
+		event := handler.envelopes[0][2]
+		values := event["exception"].(map[string]any)["values"].([]any)
+
+		require.Len(t, values, 2)
+		assert.Equal(t, "NOT_FOUND", values[0].(map[string]any)["type"])
+		assert.Equal(t, "loading", values[1].(map[string]any)["value"])
+		assert.NotEmpty(t, values[0].(map[string]any)["stacktrace"])
+		assert.Len(t, event["fingerprint"], 1)
+		assert.InDelta(t, 2, event["extra"].(map[string]any)["count"], 0)
+		assert.Equal(t, Stats{Reported: 2, Sent: 1}, reporter.Stats())
+	})
+}
@@ -0,0 +1,402 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+)
+
+// WriterSink is a Sink writing entries to an io.Writer as JSON Lines, one entry per line.
+//
+// Fields:
+//   - mu (sync.Mutex): serializes writes
+//   - w (io.Writer): where entries are written
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ Sink = (*WriterSink)(nil)
+
+// Send writes the entries, one JSON document per line.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the send (unused)
+//   - entries ([]Entry): the entries to write
+//
+// Returns:
+//   - err (error): any error from encoding or writing
+func (s *WriterSink) Send(_ context.Context, entries []Entry) (err error) {
+	data, err := encodeLines(entries)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(data)
+
+	return
+}
+
+// NewWriterSink creates a WriterSink writing to w.
+//
+// Parameters:
+//   - w (io.Writer): where entries are written
+//
+// Returns:
+//   - sink (*WriterSink): the new sink instance
+func NewWriterSink(w io.Writer) (sink *WriterSink) {
+	sink = &WriterSink{w: w}
+
+	return
+}
+
+// FileSink is a Sink appending entries to a local file as JSON Lines, and rotating it
+// when it grows past a maximum size: the file is renamed with the suffix ".1", older
+// files are shifted to ".2", ".3" and so on, and the oldest beyond the number of backups
+// are removed. Without a maximum size, the file is never rotated.
+//
+// Fields:
+//   - mu (sync.Mutex): guards file and size
+//   - path (string): path of the current file
+//   - maxSize (int64): size in bytes past which the file is rotated, or 0 for no rotation
+//   - maxBackups (int): number of rotated files kept
+//   - file (*os.File): the current file, or nil once closed
+//   - size (int64): the size of the current file
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+var (
+	_ Sink      = (*FileSink)(nil)
+	_ io.Closer = (*FileSink)(nil)
+)
+
+// Send appends the entries to the file, one JSON document per line, rotating it first
+// if they would make it grow past the maximum size.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the send (unused)
+//   - entries ([]Entry): the entries to write
+//
+// Returns:
+//   - err (error): any error from encoding, rotating or writing
+func (s *FileSink) Send(_ context.Context, entries []Entry) (err error) {
+	data, err := encodeLines(entries)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		err = hqgoerrors.New("file sink closed", hqgoerrors.WithField("path", s.path))
+
+		return
+	}
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err = s.rotate(); err != nil {
+			return
+		}
+	}
+
+	n, err := s.file.Write(data)
+
+	s.size += int64(n)
+
+	if err != nil {
+		err = hqgoerrors.Wrap(err, "writing reports", hqgoerrors.WithField("path", s.path))
+	}
+
+	return
+}
+
+// Close closes the current file. Later sends fail.
+//
+// Returns:
+//   - err (error): any error from closing the file
+func (s *FileSink) Close() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	err = s.file.Close()
+
+	s.file = nil
+
+	return
+}
+
+// rotate closes the current file, shifts the rotated files and opens a new file.
+// If shifting fails, the current file is reopened, so later sends can still append to it.
+//
+// Returns:
+//   - err (error): any error from renaming, removing or opening files
+func (s *FileSink) rotate() (err error) {
+	if err = s.file.Close(); err != nil {
+		return
+	}
+
+	s.file = nil
+
+	shiftErr := s.shift()
+
+	// reopen the file even if shifting failed, so later sends keep appending to it
+	if err = s.open(); err != nil {
+		return
+	}
+
+	if shiftErr != nil {
+		err = hqgoerrors.Wrap(shiftErr, "rotating reports file", hqgoerrors.WithField("path", s.path))
+	}
+
+	return
+}
+
+// shift renames the current file and the rotated files to the next backup number,
+// overwriting the oldest, or removes the current file if no backup is kept.
+//
+// Returns:
+//   - err (error): any error from renaming or removing files
+func (s *FileSink) shift() (err error) {
+	if s.maxBackups == 0 {
+		err = os.Remove(s.path)
+
+		return
+	}
+
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err = os.Rename(s.backup(i), s.backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+
+	err = os.Rename(s.path, s.backup(1))
+
+	return
+}
+
+// backup returns the path of the i-th rotated file.
+//
+// Parameters:
+//   - i (int): the number of the rotated file, from 1 (the most recent)
+//
+// Returns:
+//   - path (string): the path of the rotated file
+func (s *FileSink) backup(i int) (path string) {
+	path = s.path + "." + strconv.Itoa(i)
+
+	return
+}
+
+// open opens the current file for appending and records its size.
+//
+// Returns:
+//   - err (error): any error from opening the file
+func (s *FileSink) open() (err error) {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		err = hqgoerrors.Wrap(err, "opening reports file", hqgoerrors.WithField("path", s.path))
+
+		return
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		err = hqgoerrors.Wrap(err, "opening reports file", hqgoerrors.WithField("path", s.path))
+
+		return
+	}
+
+	s.file = file
+	s.size = info.Size()
+
+	return
+}
+
+// NewFileSink creates a FileSink appending to the file at path, which is created if needed.
+//
+// Parameters:
+//   - path (string): path of the file
+//   - maxSize (int64): size in bytes past which the file is rotated; 0 or less disables rotation
+//   - maxBackups (int): number of rotated files kept; with 0 the file is truncated instead
+//
+// Returns:
+//   - sink (*FileSink): the new sink instance
+//   - err (error): any error from opening the file
+func NewFileSink(path string, maxSize int64, maxBackups int) (sink *FileSink, err error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    max(maxSize, 0),
+		maxBackups: max(maxBackups, 0),
+	}
+
+	if err = s.open(); err != nil {
+		return
+	}
+
+	sink = s
+
+	return
+}
+
+// HTTPSink is a Sink posting each batch of entries to an HTTP endpoint as a JSON array.
+// Responses with a status of 429 or 5xx fail with a retryable error honoring the
+// Retry-After header, so the reporter's retry policy applies to them.
+//
+// Fields:
+//   - URL (string): the endpoint
+//   - Client (*http.Client): the client sending the requests
+//   - Header (http.Header): headers added to each request, e.g. for authentication
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+	Header http.Header
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// Send posts the entries as a JSON array.
+//
+// Parameters:
+//   - ctx (context.Context): the context of the request
+//   - entries ([]Entry): the entries to send
+//
+// Returns:
+//   - err (error): any error from encoding or sending, or an error holding the status
+//     in the "status" field if the response status is not 2xx
+func (s *HTTPSink) Send(ctx context.Context, entries []Entry) (err error) {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		err = hqgoerrors.Wrap(err, "sending reports", hqgoerrors.WithField("url", s.URL))
+
+		return
+	}
+
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return
+	}
+
+	err = statusError("sending reports", s.URL, res)
+
+	return
+}
+
+// NewHTTPSink creates an HTTPSink posting to url with http.DefaultClient.
+//
+// Parameters:
+//   - url (string): the endpoint
+//
+// Returns:
+//   - sink (*HTTPSink): the new sink instance
+func NewHTTPSink(url string) (sink *HTTPSink) {
+	sink = &HTTPSink{
+		URL:    url,
+		Client: http.DefaultClient,
+		Header: http.Header{},
+	}
+
+	return
+}
+
+// statusError returns the error of a response with a status that is not 2xx, holding the URL
+// and the status in the "url" and "status" fields. Errors for a status of 429 or 5xx are
+// retryable and honor the Retry-After header.
+//
+// Parameters:
+//   - action (string): what the request did, prefixing the message
+//   - url (string): the URL of the request
+//   - res (*http.Response): the response
+//
+// Returns:
+//   - err (error): the error
+func statusError(action, url string, res *http.Response) (err error) {
+	args := []any{
+		action,
+		res.StatusCode,
+		hqgoerrors.WithField("url", url),
+		hqgoerrors.WithField("status", res.StatusCode),
+		hqgoerrors.WithRetryable(res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500),
+	}
+
+	if seconds, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil && seconds >= 0 {
+		args = append(args, hqgoerrors.WithRetryAfter(time.Duration(seconds)*time.Second))
+	}
+
+	err = hqgoerrors.Newf("%s: unexpected status %d", args...)
+
+	return
+}
+
+// encodeLines encodes entries as JSON Lines.
+//
+// Parameters:
+//   - entries ([]Entry): the entries to encode
+//
+// Returns:
+//   - data ([]byte): one JSON document per entry, each followed by a newline
+//   - err (error): any error from encoding
+func encodeLines(entries []Entry) (data []byte, err error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	for i := range entries {
+		if err = encoder.Encode(&entries[i]); err != nil {
+			return
+		}
+	}
+
+	data = buf.Bytes()
+
+	return
+}
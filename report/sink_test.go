@@ -0,0 +1,314 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	hqgoerrors "github.com/hueristiq/hq-go-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEntries(messages ...string) (entries []Entry) {
+	for _, message := range messages {
+		err := errors.New(message)
+
+		entries = append(entries, Entry{
+			Time:        time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Fingerprint: hqgoerrors.Fingerprint(err),
+			Count:       1,
+			Error:       hqgoerrors.ToJSON(err),
+		})
+	}
+
+	return
+}
+
+func readLines(t *testing.T, r io.Reader) (entries []Entry) {
+	t.Helper()
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		var entry Entry
+
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+		entries = append(entries, entry)
+	}
+
+	require.NoError(t, scanner.Err())
+
+	return
+}
+
+func TestWriterSink(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	sink := NewWriterSink(&buf)
+
+	require.NoError(t, sink.Send(t.Context(), newTestEntries("first", "second")))
+
+	entries := readLines(t, &buf)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "second", entries[1].Error["external"].(map[string]any)["message"])
+	assert.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), entries[0].Time)
+	assert.Equal(t, 1, entries[0].Count)
+}
+
+func TestFileSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rotation", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+		sink, err := NewFileSink(path, 1, 2)
+
+		require.NoError(t, err)
+
+		for _, message := range []string{"first", "second", "third", "fourth"} {
+			require.NoError(t, sink.Send(t.Context(), newTestEntries(message)))
+		}
+
+		require.NoError(t, sink.Close())
+		require.NoError(t, sink.Close())
+
+		for path, expected := range map[string]string{path: "fourth", path + ".1": "third", path + ".2": "second"} {
+			file, err := os.Open(path)
+
+			require.NoError(t, err)
+
+			entries := readLines(t, file)
+
+			_ = file.Close()
+
+			require.Len(t, entries, 1)
+			assert.Equal(t, expected, entries[0].Error["external"].(map[string]any)["message"])
+		}
+
+		assert.NoFileExists(t, path+".3")
+
+		require.Error(t, sink.Send(t.Context(), newTestEntries("closed")))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+		for _, message := range []string{"first", "second"} {
+			sink, err := NewFileSink(path, 1<<20, 0)
+
+			require.NoError(t, err)
+			require.NoError(t, sink.Send(t.Context(), newTestEntries(message)))
+			require.NoError(t, sink.Close())
+		}
+
+		data, err := os.ReadFile(path)
+
+		require.NoError(t, err)
+		assert.Len(t, readLines(t, bytes.NewReader(data)), 2)
+	})
+
+	t.Run("without backups", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+		sink, err := NewFileSink(path, 1, 0)
+
+		require.NoError(t, err)
+		require.NoError(t, sink.Send(t.Context(), newTestEntries("first")))
+		require.NoError(t, sink.Send(t.Context(), newTestEntries("second")))
+		require.NoError(t, sink.Close())
+
+		data, err := os.ReadFile(path)
+
+		require.NoError(t, err)
+		require.Len(t, readLines(t, bytes.NewReader(data)), 1)
+		assert.NoFileExists(t, path+".1")
+	})
+
+	t.Run("without rotation", func(t *testing.T) {
+		t.Parallel()
+
+		for _, maxSize := range []int64{0, -1} {
+			path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+			sink, err := NewFileSink(path, maxSize, 2)
+
+			require.NoError(t, err)
+
+			for _, message := range []string{"first", "second", "third"} {
+				require.NoError(t, sink.Send(t.Context(), newTestEntries(message)))
+			}
+
+			require.NoError(t, sink.Close())
+
+			data, err := os.ReadFile(path)
+
+			require.NoError(t, err)
+			assert.Len(t, readLines(t, bytes.NewReader(data)), 3)
+			assert.NoFileExists(t, path+".1")
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewFileSink(filepath.Join(t.TempDir(), "missing", "errors.jsonl"), 1, 0)
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// collector is an httptest handler standing in for a remote error collector.
+// It answers with the queued statuses first, then with 204.
+type collector struct {
+	mu       sync.Mutex
+	statuses []int
+	batches  [][]Entry
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.headers = append(c.headers, r.Header.Clone())
+
+	if len(c.statuses) > 0 {
+		status := c.statuses[0]
+
+		c.statuses = c.statuses[1:]
+
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+
+		return
+	}
+
+	var batch []Entry
+
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	c.batches = append(c.batches, batch)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestHTTPSink(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &collector{}
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		sink := NewHTTPSink(server.URL)
+
+		sink.Header.Set("Authorization", "Bearer token")
+
+		require.NoError(t, sink.Send(t.Context(), newTestEntries("first", "second")))
+
+		require.Len(t, handler.batches, 1)
+		assert.Len(t, handler.batches[0], 2)
+		assert.Equal(t, "Bearer token", handler.headers[0].Get("Authorization"))
+		assert.Equal(t, "application/json", handler.headers[0].Get("Content-Type"))
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			status    int
+			retryable bool
+		}{
+			{status: http.StatusServiceUnavailable, retryable: true},
+			{status: http.StatusTooManyRequests, retryable: true},
+			{status: http.StatusBadRequest, retryable: false},
+		}
+
+		for _, tt := range tests {
+			handler := &collector{statuses: []int{tt.status}}
+
+			server := httptest.NewServer(handler)
+
+			err := NewHTTPSink(server.URL).Send(t.Context(), newTestEntries("failed"))
+
+			server.Close()
+
+			require.Error(t, err)
+			assert.Equal(t, tt.retryable, hqgoerrors.IsRetryable(err))
+			assert.Equal(t, tt.status, hqgoerrors.MustField[int](err, "status"))
+
+			_, ok := hqgoerrors.RetryAfter(err)
+
+			assert.True(t, ok)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(&collector{})
+
+		server.Close()
+
+		err := NewHTTPSink(server.URL).Send(t.Context(), newTestEntries("failed"))
+
+		require.Error(t, err)
+		assert.Equal(t, server.URL, hqgoerrors.MustField[string](err, "url"))
+	})
+
+	t.Run("reporter", func(t *testing.T) {
+		t.Parallel()
+
+		handler := &collector{statuses: []int{http.StatusServiceUnavailable}}
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		reporter := NewAsyncReporter(NewHTTPSink(server.URL))
+
+		for range 3 {
+			reporter.Report(hqgoerrors.New("not found", hqgoerrors.WithType("NOT_FOUND")))
+		}
+
+		require.NoError(t, reporter.Close())
+
+		require.Len(t, handler.batches, 1)
+		require.Len(t, handler.batches[0], 1)
+		assert.Equal(t, 3, handler.batches[0][0].Count)
+		assert.Equal(t, "NOT_FOUND", handler.batches[0][0].Error["root"].(map[string]any)["type"])
+
+		decoded := hqgoerrors.FromJSON(handler.batches[0][0].Error)
+
+		assert.True(t, hqgoerrors.IsRemote(decoded))
+		assert.ErrorIs(t, decoded, hqgoerrors.TypeMatcher("NOT_FOUND"))
+		assert.Equal(t, Stats{Reported: 3, Sent: 1}, reporter.Stats())
+	})
+}
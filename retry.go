@@ -0,0 +1,324 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RetryableField is the key of the field set by WithRetryable.
+const RetryableField = "retryable"
+
+// RetryAfterField is the key of the field set by WithRetryAfter.
+const RetryAfterField = "retry_after"
+
+// retryableTypes holds the retryability registered per type with RegisterRetryableType.
+// It is written at registration, typically during package initialization, and read by
+// IsRetryable, so it uses a sync.Map.
+var retryableTypes sync.Map
+
+// RegisterRetryableType registers whether errors of type errType are worth retrying.
+// The rule also applies to the descendants of errType (see RegisterType), unless they
+// have a rule of their own.
+//
+// Parameters:
+//   - errType (Type): the error type
+//   - retryable (bool): whether errors of the type are worth retrying
+func RegisterRetryableType(errType Type, retryable bool) {
+	retryableTypes.Store(errType, retryable)
+}
+
+// WithRetryable creates an OptionFunc that explicitly marks whether the error is worth retrying.
+// The mark is stored in the RetryableField field and takes precedence over every other rule
+// in IsRetryable.
+//
+// Parameters:
+//   - retryable (bool): whether the error is worth retrying
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithRetryable(retryable bool) (f OptionFunc) {
+	return WithField(RetryableField, retryable)
+}
+
+// WithRetryAfter creates an OptionFunc that sets how long to wait before retrying,
+// e.g. from a Retry-After header. It is stored in the RetryAfterField field and
+// also marks the error as retryable, unless WithRetryable says otherwise.
+//
+// Parameters:
+//   - delay (time.Duration): the delay before the next attempt
+//
+// Returns:
+//   - f (OptionFunc): configuration function for New/Wrap
+func WithRetryAfter(delay time.Duration) (f OptionFunc) {
+	return func(err Error) {
+		err.SetField(RetryAfterField, delay)
+
+		if _, ok := err.Fields()[RetryableField]; !ok {
+			err.SetField(RetryableField, true)
+		}
+	}
+}
+
+// IsRetryable reports whether an operation that failed with err is worth retrying.
+// It walks err's tree, outermost error first, and decides by the first rule that applies:
+//
+//  1. An explicit mark set with WithRetryable (or WithRetryAfter).
+//  2. A rule registered with RegisterRetryableType for an error's type or one of its ancestors.
+//  3. An error implementing Timeout() bool or Temporary() bool that returns true,
+//     which covers net.Error and context.DeadlineExceeded.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - retryable (bool): true if err is worth retrying, false otherwise or if err is nil
+func IsRetryable(err error) (retryable bool) {
+	if err == nil {
+		return
+	}
+
+	for node := range All(err) {
+		if x, ok := node.(interface{ Fields() map[string]any }); ok {
+			if marked, ok := x.Fields()[RetryableField].(bool); ok {
+				retryable = marked
+
+				return
+			}
+		}
+	}
+
+	for node := range All(err) {
+		for t := typeOf(node); t != ""; t = t.Parent() {
+			if v, ok := retryableTypes.Load(t); ok {
+				retryable, _ = v.(bool)
+
+				return
+			}
+		}
+	}
+
+	for node := range All(err) {
+		if x, ok := node.(interface{ Timeout() bool }); ok && x.Timeout() {
+			retryable = true
+
+			return
+		}
+
+		if x, ok := node.(interface{ Temporary() bool }); ok && x.Temporary() {
+			retryable = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// RetryAfter returns the delay set with WithRetryAfter on the outermost error of err's tree holding one.
+// Besides time.Duration values, it accepts the numbers of nanoseconds and the duration strings
+// (e.g. "1.5s") found in the fields of errors decoded from JSON (see FromJSON).
+//
+// Parameters:
+//   - err (error): the error to inspect
+//
+// Returns:
+//   - delay (time.Duration): the delay before the next attempt, or zero if none is set
+//   - ok (bool): true if a delay was found
+func RetryAfter(err error) (delay time.Duration, ok bool) {
+	for node := range All(err) {
+		x, isFielded := node.(interface{ Fields() map[string]any })
+		if !isFielded {
+			continue
+		}
+
+		value, found := x.Fields()[RetryAfterField]
+		if !found {
+			continue
+		}
+
+		delay, ok = durationOf(value)
+
+		return
+	}
+
+	return
+}
+
+// durationOf converts a field value holding a duration, as set by WithRetryAfter or as
+// decoded from JSON, into a time.Duration.
+//
+// Parameters:
+//   - value (any): the field value
+//
+// Returns:
+//   - duration (time.Duration): the duration
+//   - ok (bool): true if value holds a duration
+func durationOf(value any) (duration time.Duration, ok bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		duration, ok = v, true
+	case int:
+		duration, ok = time.Duration(v), true
+	case int64:
+		duration, ok = time.Duration(v), true
+	case float64:
+		duration, ok = time.Duration(v), true
+	case json.Number:
+		n, err := v.Int64()
+
+		duration, ok = time.Duration(n), err == nil
+	case string:
+		d, err := time.ParseDuration(v)
+
+		duration, ok = d, err == nil
+	}
+
+	return
+}
+
+// RetryPolicy configures Retry. The zero value makes 3 attempts with an exponential
+// backoff starting at 100ms, doubling up to 10s, without jitter.
+//
+// Fields:
+//   - MaxAttempts (int): maximum number of attempts, including the first; defaults to 3
+//   - InitialDelay (time.Duration): delay before the second attempt; defaults to 100ms
+//   - MaxDelay (time.Duration): upper bound of the delay between attempts; defaults to 10s
+//   - Multiplier (float64): factor applied to the delay after each attempt; defaults to 2
+//   - Jitter (float64): fraction of each delay, between 0 and 1, randomly removed from it
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+}
+
+// Default values of RetryPolicy fields.
+const (
+	defaultRetryMaxAttempts  = 3
+	defaultRetryInitialDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay     = 10 * time.Second
+	defaultRetryMultiplier   = 2
+)
+
+// delay returns the delay before the attempt following attempt (counted from 1),
+// applying the policy defaults, the backoff and the jitter.
+//
+// Parameters:
+//   - attempt (int): the number of the failed attempt
+//
+// Returns:
+//   - d (time.Duration): the delay before the next attempt
+func (p RetryPolicy) delay(attempt int) (d time.Duration) {
+	initial := p.InitialDelay
+	if initial <= 0 {
+		initial = defaultRetryInitialDelay
+	}
+
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultRetryMultiplier
+	}
+
+	next := float64(initial)
+
+	for i := 1; i < attempt && next < float64(maxDelay); i++ {
+		next *= multiplier
+	}
+
+	next = min(next, float64(maxDelay))
+
+	if p.Jitter > 0 {
+		next -= next * min(p.Jitter, 1) * rand.Float64()
+	}
+
+	d = time.Duration(next)
+
+	return
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not worth retrying
+// (see IsRetryable), the policy's attempts are exhausted, or ctx is done.
+// Between attempts it waits for the policy's backoff delay, or for the delay set
+// with WithRetryAfter on the error, if any.
+//
+// On failure, every attempt's error is returned in one joined error with the number of
+// attempts in the "attempts" field and a stack trace at the caller. If ctx is done while
+// waiting, the reason (see FromContext) is joined as well.
+//
+// Parameters:
+//   - ctx (context.Context): the context bounding the retries
+//   - policy (RetryPolicy): the retry policy
+//   - fn (func() error): the operation to retry
+//
+// Returns:
+//   - err (error): nil if an attempt succeeds, otherwise the joined errors of all attempts
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) (err error) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+
+	var errs []error
+
+	attempt := 0
+
+	for attempt < maxAttempts {
+		attempt++
+
+		attemptErr := fn()
+		if attemptErr == nil {
+			return
+		}
+
+		errs = append(errs, attemptErr)
+
+		if attempt == maxAttempts || !IsRetryable(attemptErr) {
+			break
+		}
+
+		delay, ok := RetryAfter(attemptErr)
+		if !ok {
+			delay = policy.delay(attempt)
+		}
+
+		if waitErr := wait(ctx, delay); waitErr != nil {
+			errs = append(errs, waitErr)
+
+			break
+		}
+	}
+
+	err = JoinWith(errs, WithField("attempts", attempt), WithCallerSkip(1))
+
+	return
+}
+
+// wait blocks for delay or until ctx is done.
+//
+// Parameters:
+//   - ctx (context.Context): the context bounding the wait
+//   - delay (time.Duration): how long to wait
+//
+// Returns:
+//   - err (error): nil after the delay, or the reason ctx is done (see FromContext)
+func wait(ctx context.Context, delay time.Duration) (err error) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = FromContext(ctx)
+	}
+
+	return
+}
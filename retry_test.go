@@ -0,0 +1,233 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type temporaryError struct {
+	timeout   bool
+	temporary bool
+}
+
+func (e *temporaryError) Error() string   { return "temporary" }
+func (e *temporaryError) Timeout() bool   { return e.timeout }
+func (e *temporaryError) Temporary() bool { return e.temporary }
+
+const (
+	retryTestParentType Type = "TEST_RETRY_PARENT"
+	retryTestChildType  Type = "TEST_RETRY_CHILD"
+	retryTestOtherType  Type = "TEST_RETRY_OTHER"
+)
+
+func init() {
+	RegisterType(retryTestChildType, retryTestParentType)
+	RegisterType(retryTestOtherType, retryTestParentType)
+	RegisterRetryableType(retryTestParentType, true)
+	RegisterRetryableType(retryTestOtherType, false)
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "plain", err: New("failed"), expected: false},
+		{name: "marked", err: New("failed", WithRetryable(true)), expected: true},
+		{name: "marked below", err: Wrap(New("failed", WithRetryable(true)), "wrapper"), expected: true},
+		{name: "outer mark wins", err: Wrap(New("failed", WithRetryable(true)), "wrapper", WithRetryable(false)), expected: false},
+		{name: "mark wins over type", err: New("failed", WithType(retryTestParentType), WithRetryable(false)), expected: false},
+		{name: "retry after", err: New("failed", WithRetryAfter(time.Second)), expected: true},
+		{name: "retry after marked", err: New("failed", WithRetryAfter(time.Second), WithRetryable(false)), expected: false},
+		{name: "registered type", err: New("failed", WithType(retryTestParentType)), expected: true},
+		{name: "inherited type", err: New("failed", WithType(retryTestChildType)), expected: true},
+		{name: "overridden type", err: New("failed", WithType(retryTestOtherType)), expected: false},
+		{name: "type wins over timeout", err: Wrap(&temporaryError{timeout: true}, "wrapper", WithType(retryTestOtherType)), expected: false},
+		{name: "timeout", err: Wrap(&temporaryError{timeout: true}, "wrapper"), expected: true},
+		{name: "temporary", err: fmt.Errorf("wrapper: %w", &temporaryError{temporary: true}), expected: true},
+		{name: "neither", err: &temporaryError{}, expected: false},
+		{name: "net error", err: Wrap(&net.DNSError{Err: "timeout", IsTimeout: true}, "resolving"), expected: true},
+		{name: "joined", err: Join(New("failed"), New("failed", WithRetryable(true))), expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, IsRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	delay, ok := RetryAfter(Wrap(New("failed", WithRetryAfter(time.Second)), "wrapper"))
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+
+	_, ok = RetryAfter(New("failed"))
+
+	assert.False(t, ok)
+
+	decoded, err := FromJSONString(ToJSONString(Wrap(New("failed", WithRetryAfter(1500*time.Millisecond)), "wrapper")))
+
+	require.NoError(t, err)
+
+	delay, ok = RetryAfter(decoded)
+
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, delay)
+	assert.True(t, IsRetryable(decoded))
+
+	for _, value := range []any{int64(time.Second), json.Number("1000000000"), "1s"} {
+		delay, ok = RetryAfter(New("failed", WithField(RetryAfterField, value)))
+
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, delay)
+	}
+
+	_, ok = RetryAfter(New("failed", WithField(RetryAfterField, "soon")))
+
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+	assert.Equal(t, 5*time.Second, policy.delay(100))
+
+	assert.Equal(t, defaultRetryInitialDelay, RetryPolicy{}.delay(1))
+
+	policy.Jitter = 0.5
+
+	for range 100 {
+		d := policy.delay(1)
+
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, time.Second)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	t.Run("success after retries", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		err := Retry(t.Context(), policy, func() error {
+			calls++
+
+			if calls < 3 {
+				return New("unavailable", WithRetryable(true))
+			}
+
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		err := Retry(t.Context(), policy, func() error {
+			calls++
+
+			return Newf("attempt %d", calls, WithRetryable(true))
+		})
+
+		require.Error(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, "attempt 1\nattempt 2\nattempt 3", err.Error())
+		assert.Equal(t, 3, err.(*joined).Fields()["attempts"])
+		assert.Contains(t, err.(*joined).stackFrames()[0].Name, "TestRetry")
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+
+		permanent := New("invalid")
+
+		err := Retry(t.Context(), policy, func() error {
+			calls++
+
+			return permanent
+		})
+
+		require.IsType(t, &joined{}, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, []error{permanent}, err.(*joined).Unwrap())
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts []time.Time
+
+		err := Retry(t.Context(), RetryPolicy{MaxAttempts: 2, InitialDelay: time.Hour}, func() error {
+			attempts = append(attempts, time.Now())
+
+			return New("throttled", WithRetryAfter(10*time.Millisecond))
+		})
+
+		require.Error(t, err)
+		require.Len(t, attempts, 2)
+		assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), 10*time.Millisecond)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		err := Retry(ctx, RetryPolicy{MaxAttempts: 5, InitialDelay: time.Hour}, func() error {
+			cancel()
+
+			return New("unavailable", WithRetryable(true))
+		})
+
+		require.Error(t, err)
+		assert.True(t, IsCanceled(err))
+		assert.Len(t, err.(*joined).Unwrap(), 2)
+		assert.Equal(t, 1, err.(*joined).Fields()["attempts"])
+	})
+
+	t.Run("external errors", func(t *testing.T) {
+		t.Parallel()
+
+		external := errors.New("external")
+
+		err := Retry(t.Context(), policy, func() error { return external })
+
+		assert.ErrorIs(t, err, external)
+	})
+}
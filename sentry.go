@@ -0,0 +1,540 @@
+package errors
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentryEvent is an error event in the format of Sentry's event payload,
+// ready to be marshaled to JSON and sent to Sentry or a compatible service.
+//
+// Fields:
+//   - EventID (string): a random identifier of 32 hexadecimal characters
+//   - Timestamp (time.Time): when the event was created
+//   - Platform (string): the platform of the event, always "go"
+//   - Level (string): "fatal" for errors of type TypePanic, "error" otherwise
+//   - Release (string): the release of the application, if set by the caller
+//   - Environment (string): the environment of the application, if set by the caller
+//   - Exception (SentryExceptions): the exceptions of the error, innermost first
+//   - Tags (map[string]string): the fields of the error with short scalar values
+//   - Extra (map[string]any): the other fields of the error
+//   - Fingerprint ([]string): the error's fingerprint (see Fingerprint), computed with the
+//     formatter's frame filters and used by Sentry for grouping
+type SentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Exception   SentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	Fingerprint []string          `json:"fingerprint,omitempty"`
+}
+
+// SentryExceptions is the exception interface of a SentryEvent.
+//
+// Fields:
+//   - Values ([]SentryException): the exceptions, innermost first and outermost last, as Sentry expects
+type SentryExceptions struct {
+	Values []SentryException `json:"values"`
+}
+
+// SentryException is a single part of an error in a SentryEvent.
+//
+// Fields:
+//   - Type (string): the type of the part, or its Go type name for external errors
+//   - Value (string): the message of the part
+//   - Stacktrace (*SentryStacktrace): the stack trace of the part, if any
+//   - Mechanism (*SentryMechanism): how the part relates to the other exceptions
+type SentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *SentryStacktrace `json:"stacktrace,omitempty"`
+	Mechanism  *SentryMechanism  `json:"mechanism,omitempty"`
+}
+
+// SentryStacktrace is the stack trace of a SentryException.
+//
+// Fields:
+//   - Frames ([]SentryFrame): the frames, oldest first, as Sentry expects
+type SentryStacktrace struct {
+	Frames []SentryFrame `json:"frames"`
+}
+
+// SentryFrame is a single frame of a SentryStacktrace.
+//
+// Fields:
+//   - Function (string): the function name, without its package (e.g. "(*Server).Serve")
+//   - Module (string): the import path of the function's package
+//   - Filename (string): the file, as formatted (possibly trimmed)
+//   - AbsPath (string): the file, if it is an absolute path
+//   - Lineno (int): the line number
+//   - InApp (bool): true for frames of the main module
+type SentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// SentryMechanism describes how a SentryException relates to the others, letting Sentry
+// render chains of wraps and joined errors (exception groups) as a tree.
+//
+// Fields:
+//   - Type (string): "generic" for the outermost exception, "chained" for the others
+//   - ExceptionID (int): the identifier of the exception, 0 for the outermost one
+//   - ParentID (*int): the identifier of the exception this one is the cause of, if any
+//   - Source (string): "cause" for wrapped errors, "errors[i]" for the i-th joined error
+//   - IsExceptionGroup (bool): true for joined errors
+type SentryMechanism struct {
+	Type             string `json:"type"`
+	ExceptionID      int    `json:"exception_id"`
+	ParentID         *int   `json:"parent_id,omitempty"`
+	Source           string `json:"source,omitempty"`
+	IsExceptionGroup bool   `json:"is_exception_group,omitempty"`
+}
+
+const (
+	// sentryUntyped is the exception type of package error parts without a type.
+	sentryUntyped = "error"
+
+	// sentryMaxTagKey and sentryMaxTagValue are Sentry's limits on tag keys and values.
+	// Longer fields are sent as extra data instead.
+	sentryMaxTagKey   = 32
+	sentryMaxTagValue = 200
+)
+
+// sentryBuilder accumulates the exceptions and fields of a SentryEvent while walking an unpacked error.
+//
+// Fields:
+//   - formatter (*Formatter): formats fields and stack frames
+//   - exceptions ([]SentryException): the exceptions, outermost first
+//   - fields (map[string]any): the fields of all parts, outer parts taking precedence
+type sentryBuilder struct {
+	formatter  *Formatter
+	exceptions []SentryException
+	fields     map[string]any
+}
+
+// addTree adds the exceptions of an unpacked error, outermost first, each one the cause of the previous.
+//
+// Parameters:
+//   - unpacked (*UnpackedError): the unpacked error
+//   - parentID (*int): the identifier of the exception the tree is the cause of, or nil for the top level
+//   - source (string): how the tree relates to its parent
+func (b *sentryBuilder) addTree(unpacked *UnpackedError, parentID *int, source string) {
+	for i := range unpacked.ErrChain {
+		parentID = b.addPart(&unpacked.ErrChain[i], parentID, source)
+		source = "cause"
+	}
+
+	if b.formatter.hasRootContent(&unpacked.ErrRoot) {
+		parentID = b.addPart(&unpacked.ErrRoot, parentID, source)
+		source = "cause"
+	}
+
+	if unpacked.ErrJoin != nil {
+		b.addJoined(unpacked, parentID, source)
+	}
+
+	if unpacked.ErrExternal != nil && (b.formatter.options.WithExternal || b.formatter.isOnlyExternal(unpacked)) {
+		b.add(SentryException{
+			Type:  goType(unpacked.ErrExternal),
+			Value: unpacked.ErrExternal.Error(),
+		}, parentID, source)
+	}
+}
+
+// addPart adds the exception of an error part and merges its fields.
+//
+// Parameters:
+//   - part (*ErrPart): the error part
+//   - parentID (*int): the identifier of the parent exception, or nil
+//   - source (string): how the part relates to its parent
+//
+// Returns:
+//   - id (*int): the identifier of the added exception
+func (b *sentryBuilder) addPart(part *ErrPart, parentID *int, source string) (id *int) {
+	exception := SentryException{
+		Type:       string(part.Type),
+		Value:      part.Message,
+		Stacktrace: b.stacktrace(part.Stack),
+	}
+
+	if exception.Type == "" {
+		exception.Type = sentryUntyped
+
+		if part.External != nil {
+			exception.Type = goType(part.External)
+		}
+	}
+
+	b.merge(part.Fields)
+
+	id = b.add(exception, parentID, source)
+
+	return
+}
+
+// addJoined adds the exception group of the joined error ending an unpacked chain,
+// then the trees of its errors as its children.
+//
+// Parameters:
+//   - unpacked (*UnpackedError): the unpacked error holding the joined error
+//   - parentID (*int): the identifier of the parent exception, or nil
+//   - source (string): how the joined error relates to its parent
+func (b *sentryBuilder) addJoined(unpacked *UnpackedError, parentID *int, source string) {
+	exception := SentryException{
+		Type:  goType(unpacked.ErrJoin),
+		Value: fmt.Sprintf("Multiple errors (%d)", len(unpacked.ErrJoinedTrees)),
+	}
+
+	if joinErr, ok := unpacked.ErrJoin.(*joined); ok {
+		exception.Type = "joined"
+
+		if errType := joinErr.Type(); errType != "" {
+			exception.Type = string(errType)
+		}
+
+		if joinErr.message != "" {
+			exception.Value = joinErr.message
+		}
+
+		exception.Stacktrace = b.stacktrace(joinErr.stackFrames())
+
+		b.merge(joinErr.Fields())
+	}
+
+	id := b.add(exception, parentID, source)
+
+	b.exceptions[*id].Mechanism.IsExceptionGroup = true
+
+	for i := range unpacked.ErrJoinedTrees {
+		b.addTree(&unpacked.ErrJoinedTrees[i], id, "errors["+strconv.Itoa(i)+"]")
+	}
+}
+
+// add appends an exception with its mechanism.
+//
+// Parameters:
+//   - exception (SentryException): the exception
+//   - parentID (*int): the identifier of the parent exception, or nil
+//   - source (string): how the exception relates to its parent
+//
+// Returns:
+//   - id (*int): the identifier of the added exception
+func (b *sentryBuilder) add(exception SentryException, parentID *int, source string) (id *int) {
+	exceptionID := len(b.exceptions)
+
+	exception.Mechanism = &SentryMechanism{
+		Type:        "chained",
+		ExceptionID: exceptionID,
+		ParentID:    parentID,
+		Source:      source,
+	}
+
+	if parentID == nil {
+		exception.Mechanism.Type = "generic"
+	}
+
+	b.exceptions = append(b.exceptions, exception)
+
+	id = &exceptionID
+
+	return
+}
+
+// merge adds the fields of a part that are not already set by an outer part.
+//
+// Parameters:
+//   - fields (map[string]any): the fields of the part
+func (b *sentryBuilder) merge(fields map[string]any) {
+	for key, value := range b.formatter.fields(fields) {
+		if _, ok := b.fields[key]; !ok {
+			b.fields[key] = value
+		}
+	}
+}
+
+// stacktrace converts stack frames, most recent first, into a Sentry stack trace, oldest first.
+//
+// Parameters:
+//   - stack (Stack): the frames
+//
+// Returns:
+//   - stacktrace (*SentryStacktrace): the stack trace, or nil if no frame is left after filtering
+func (b *sentryBuilder) stacktrace(stack Stack) (stacktrace *SentryStacktrace) {
+	frames := b.formatter.stack(stack)
+
+	if len(frames) == 0 {
+		return
+	}
+
+	stacktrace = &SentryStacktrace{Frames: make([]SentryFrame, 0, len(frames))}
+
+	for _, frame := range slices.Backward(frames) {
+		module, function := splitFunctionName(functionName(frame))
+
+		sentryFrame := SentryFrame{
+			Function: function,
+			Module:   module,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+			InApp:    isInApp(frame),
+		}
+
+		if filepath.IsAbs(frame.File) {
+			sentryFrame.AbsPath = frame.File
+		}
+
+		stacktrace.Frames = append(stacktrace.Frames, sentryFrame)
+	}
+
+	return
+}
+
+// SentryEvent converts the error into a Sentry event. Stack traces are always included;
+// the other options (frame filters, path trimming, redaction) apply as in JSON, and the
+// frame filters also apply to the fingerprint, so it only depends on the frames sent.
+// The fields of all parts are merged, outer parts taking precedence: short scalar
+// values become tags, the others extra data.
+//
+// Parameters:
+//   - err (error): the error to convert
+//
+// Returns:
+//   - event (*SentryEvent): the event, or nil if err is nil
+func (f *Formatter) SentryEvent(err error) (event *SentryEvent) {
+	if err == nil {
+		return
+	}
+
+	fingerprintOptions := make([]FingerprintOptionFunc, 0, len(f.options.FrameFilters))
+
+	for _, filter := range f.options.FrameFilters {
+		fingerprintOptions = append(fingerprintOptions, FingerprintWithFrameFilter(filter))
+	}
+
+	event = &SentryEvent{
+		EventID:     newEventID(),
+		Timestamp:   time.Now().UTC(),
+		Platform:    "go",
+		Level:       "error",
+		Fingerprint: []string{Fingerprint(err, fingerprintOptions...)},
+	}
+
+	if IsType(err, TypePanic) {
+		event.Level = "fatal"
+	}
+
+	if f.options.Redact {
+		err = redact(err, f.options.SensitiveFields)
+	}
+
+	unpacked := Unpack(err)
+
+	options := *f.options
+
+	options.WithTrace = true
+
+	b := &sentryBuilder{
+		formatter: &Formatter{options: &options},
+		fields:    map[string]any{},
+	}
+
+	b.addTree(&unpacked, nil, "")
+
+	slices.Reverse(b.exceptions)
+
+	event.Exception.Values = b.exceptions
+
+	for key, value := range b.fields {
+		if tag, ok := sentryTag(key, value); ok {
+			if event.Tags == nil {
+				event.Tags = map[string]string{}
+			}
+
+			event.Tags[key] = tag
+
+			continue
+		}
+
+		if event.Extra == nil {
+			event.Extra = map[string]any{}
+		}
+
+		event.Extra[key] = value
+	}
+
+	return
+}
+
+// ToSentryEvent is a convenience function to convert an error into a Sentry event.
+// It creates a formatter with options and calls SentryEvent.
+//
+// Parameters:
+//   - err (error): the error to convert
+//   - ofs (...FormatterOptionFunc): optional configuration
+//
+// Returns:
+//   - event (*SentryEvent): the event, or nil if err is nil
+func ToSentryEvent(err error, ofs ...FormatterOptionFunc) (event *SentryEvent) {
+	formatter := NewFormatter(ofs...)
+
+	event = formatter.SentryEvent(err)
+
+	return
+}
+
+// sentryTag returns the tag value of a field, if it fits Sentry's tag limits.
+//
+// Parameters:
+//   - key (string): the field key
+//   - value (any): the field value
+//
+// Returns:
+//   - tag (string): the value as a string
+//   - ok (bool): true if the field is a short scalar that can be a tag
+func sentryTag(key string, value any) (tag string, ok bool) {
+	if key == "" || len(key) > sentryMaxTagKey {
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		tag = value
+	case Type:
+		tag = string(value)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		tag = fmt.Sprint(value)
+	default:
+		return
+	}
+
+	ok = tag != "" && len(tag) <= sentryMaxTagValue
+
+	return
+}
+
+// splitFunctionName splits a fully qualified function name into its package import path
+// and the function name within the package.
+//
+// Parameters:
+//   - name (string): the function name (e.g. "github.com/org/app/pkg.(*T).Method")
+//
+// Returns:
+//   - module (string): the import path (e.g. "github.com/org/app/pkg")
+//   - function (string): the function name (e.g. "(*T).Method")
+func splitFunctionName(name string) (module, function string) {
+	slash := strings.LastIndex(name, "/") + 1
+
+	dot := strings.Index(name[slash:], ".")
+	if dot < 0 {
+		function = name
+
+		return
+	}
+
+	module = name[:slash+dot]
+	function = name[slash+dot+1:]
+
+	return
+}
+
+// mainModule returns the import path of the main module of the running binary, or empty
+// if the binary was built without module information.
+var mainModule = sync.OnceValue(func() (path string) {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		path = info.Main.Path
+	}
+
+	return
+})
+
+// isInApp reports whether a frame belongs to the application rather than to the standard
+// library or a dependency. Frames are classified by the import path of their function,
+// which path trimming does not affect: in app if it is the main package or in the main
+// module, or, without module information, if its first element has a dot (unlike the
+// standard library) and its file is not in the module cache or a vendor directory.
+// Frames decoded from JSON only have a simplified function name, so only their file tells.
+//
+// Parameters:
+//   - frame (StackFrame): the frame
+//
+// Returns:
+//   - inApp (bool): true if the frame belongs to the application
+func isInApp(frame StackFrame) (inApp bool) {
+	file := filepath.ToSlash(frame.File)
+
+	if frame.Function == "" {
+		if strings.Contains(file, "/pkg/mod/") || strings.Contains(file, "/vendor/") {
+			return
+		}
+
+		inApp = build.Default.GOROOT == "" || !strings.HasPrefix(file, filepath.ToSlash(filepath.Join(build.Default.GOROOT, "src"))+"/")
+
+		return
+	}
+
+	module, _ := splitFunctionName(frame.Function)
+
+	inApp = isInModule(module, mainModule(), file)
+
+	return
+}
+
+// isInModule reports whether a package belongs to the application's module.
+//
+// Parameters:
+//   - module (string): the import path of the package
+//   - main (string): the import path of the main module, or empty if unknown
+//   - file (string): a file of the package, used when the main module is unknown
+//
+// Returns:
+//   - inModule (bool): true if the package belongs to the application
+func isInModule(module, main, file string) (inModule bool) {
+	if module == "main" {
+		inModule = true
+
+		return
+	}
+
+	if main != "" && main != "command-line-arguments" {
+		inModule = module == main || strings.HasPrefix(module, main+"/")
+
+		return
+	}
+
+	first, _, _ := strings.Cut(module, "/")
+
+	inModule = strings.Contains(first, ".") && !strings.Contains(file, "/pkg/mod/") && !strings.Contains(file, "/vendor/")
+
+	return
+}
+
+// newEventID returns a random Sentry event identifier.
+//
+// Returns:
+//   - id (string): 32 hexadecimal characters
+func newEventID() (id string) {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+
+	id = hex.EncodeToString(b[:])
+
+	return
+}
@@ -0,0 +1,255 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSentryEvent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, ToSentryEvent(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		t.Parallel()
+
+		root := New("not found", WithType("NOT_FOUND"), WithField("order_id", 42), WithField("ids", []int{1, 2}))
+		err := Wrap(root, "loading order", WithField("order_id", 7), WithField("user", "alice"))
+
+		event := ToSentryEvent(err)
+
+		require.NotNil(t, event)
+		assert.Len(t, event.EventID, 32)
+		assert.Equal(t, "go", event.Platform)
+		assert.Equal(t, "error", event.Level)
+		assert.False(t, event.Timestamp.IsZero())
+		assert.Equal(t, []string{Fingerprint(err)}, event.Fingerprint)
+		assert.Equal(t, map[string]string{"order_id": "7", "user": "alice"}, event.Tags)
+		assert.Equal(t, map[string]any{"ids": []int{1, 2}}, event.Extra)
+
+		values := event.Exception.Values
+
+		require.Len(t, values, 2)
+
+		assert.Equal(t, "NOT_FOUND", values[0].Type)
+		assert.Equal(t, "not found", values[0].Value)
+		require.NotNil(t, values[0].Mechanism.ParentID)
+		assert.Equal(t, 0, *values[0].Mechanism.ParentID)
+		assert.Equal(t, SentryMechanism{Type: "chained", ExceptionID: 1, ParentID: values[0].Mechanism.ParentID, Source: "cause"}, *values[0].Mechanism)
+
+		assert.Equal(t, "error", values[1].Type)
+		assert.Equal(t, "loading order", values[1].Value)
+		assert.Equal(t, &SentryMechanism{Type: "generic"}, values[1].Mechanism)
+
+		frames := values[0].Stacktrace.Frames
+
+		require.NotEmpty(t, frames)
+
+		last := frames[len(frames)-1]
+
+		assert.Equal(t, "github.com/hueristiq/hq-go-errors", last.Module)
+		assert.Equal(t, "TestToSentryEvent.func2", last.Function)
+		assert.Equal(t, last.Filename, last.AbsPath)
+		assert.True(t, strings.HasSuffix(last.Filename, "sentry_test.go"))
+		assert.True(t, last.InApp)
+		assert.Equal(t, "testing", frames[0].Module)
+		assert.False(t, frames[0].InApp)
+
+		require.NotNil(t, values[1].Stacktrace)
+		assert.Len(t, values[1].Stacktrace.Frames, 1)
+	})
+
+	t.Run("external", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(errors.New("connection refused"), "dialing")
+
+		values := ToSentryEvent(err).Exception.Values
+
+		require.Len(t, values, 2)
+		assert.Equal(t, "*errors.errorString", values[0].Type)
+		assert.Equal(t, "connection refused", values[0].Value)
+		assert.Nil(t, values[0].Stacktrace)
+		assert.Equal(t, "dialing", values[1].Value)
+
+		values = ToSentryEvent(fmt.Errorf("handler: %w", New("failed"))).Exception.Values
+
+		require.Len(t, values, 2)
+		assert.Equal(t, "*fmt.wrapError", values[1].Type)
+		assert.Equal(t, "failed", values[0].Value)
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(JoinWith([]error{New("first"), errors.New("second")}, WithType("BATCH")), "processing")
+
+		values := ToSentryEvent(err).Exception.Values
+
+		require.Len(t, values, 4)
+
+		assert.Equal(t, "second", values[0].Value)
+		require.NotNil(t, values[0].Mechanism.ParentID)
+		assert.Equal(t, 1, *values[0].Mechanism.ParentID)
+		assert.Equal(t, 3, values[0].Mechanism.ExceptionID)
+		assert.Equal(t, "errors[1]", values[0].Mechanism.Source)
+		assert.Equal(t, "first", values[1].Value)
+		assert.Equal(t, "errors[0]", values[1].Mechanism.Source)
+		assert.Equal(t, "BATCH", values[2].Type)
+		assert.Equal(t, "Multiple errors (2)", values[2].Value)
+		assert.True(t, values[2].Mechanism.IsExceptionGroup)
+		assert.NotNil(t, values[2].Stacktrace)
+		assert.Equal(t, "processing", values[3].Value)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(FromPanic("boom"), "serving")
+
+		assert.Equal(t, "fatal", ToSentryEvent(err).Level)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("SELECT * FROM users", WithField("password", "hunter2"), WithField("query", strings.Repeat("x", 300)))
+
+		filter := func(frame StackFrame) bool {
+			return !strings.HasPrefix(functionName(frame), "testing.") && !strings.HasPrefix(functionName(frame), "runtime.")
+		}
+
+		event := ToSentryEvent(err, FormatRedacted(), FormatWithFrameFilter(filter))
+
+		assert.Equal(t, RedactedPlaceholder, event.Exception.Values[0].Value)
+		assert.Equal(t, map[string]string{"password": RedactedPlaceholder, "query": RedactedPlaceholder}, event.Tags)
+		assert.Equal(t, []string{Fingerprint(err, FingerprintWithFrameFilter(filter))}, event.Fingerprint)
+		assert.NotEqual(t, []string{Fingerprint(err)}, event.Fingerprint)
+
+		for _, frame := range event.Exception.Values[0].Stacktrace.Frames {
+			assert.NotEqual(t, "testing", frame.Module)
+		}
+
+		event = ToSentryEvent(err)
+
+		assert.Equal(t, map[string]any{"query": strings.Repeat("x", 300)}, event.Extra)
+		assert.Equal(t, map[string]string{"password": RedactedPlaceholder}, event.Tags)
+	})
+
+	t.Run("trimmed paths", func(t *testing.T) {
+		t.Parallel()
+
+		frames := ToSentryEvent(New("failed"), FormatTrimGoPaths(), FormatTrimModuleRoot()).Exception.Values[0].Stacktrace.Frames
+
+		require.NotEmpty(t, frames)
+
+		last := frames[len(frames)-1]
+
+		assert.Equal(t, "sentry_test.go", last.Filename)
+		assert.Empty(t, last.AbsPath)
+		assert.True(t, last.InApp)
+		assert.Equal(t, "testing/testing.go", frames[0].Filename)
+		assert.False(t, frames[0].InApp)
+	})
+
+	t.Run("decoded", func(t *testing.T) {
+		t.Parallel()
+
+		err := FromJSON(ToJSON(New("not found", WithType("NOT_FOUND")), FormatWithTrace()))
+
+		frames := ToSentryEvent(err).Exception.Values[0].Stacktrace.Frames
+
+		require.NotEmpty(t, frames)
+
+		last := frames[len(frames)-1]
+
+		assert.Equal(t, "hq-go-errors", last.Module)
+		assert.Equal(t, "TestToSentryEvent.func8", last.Function)
+		assert.True(t, last.InApp)
+		assert.False(t, frames[0].InApp)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(ToSentryEvent(New("failed")))
+
+		require.NoError(t, err)
+
+		var payload map[string]any
+
+		require.NoError(t, json.Unmarshal(data, &payload))
+
+		exception := payload["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+
+		assert.Equal(t, "failed", exception["value"])
+		assert.Contains(t, exception["stacktrace"].(map[string]any)["frames"].([]any)[0], "lineno")
+		assert.NotContains(t, payload, "tags")
+	})
+}
+
+func TestSplitFunctionName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		module   string
+		function string
+	}{
+		{name: "github.com/org/app/pkg.(*T).Method", module: "github.com/org/app/pkg", function: "(*T).Method"},
+		{name: "github.com/org/app.v2/pkg.Func.func1", module: "github.com/org/app.v2/pkg", function: "Func.func1"},
+		{name: "main.main", module: "main", function: "main"},
+		{name: "errors.New", module: "errors", function: "New"},
+		{name: "unknown", function: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			module, function := splitFunctionName(tt.name)
+
+			assert.Equal(t, tt.module, module)
+			assert.Equal(t, tt.function, function)
+		})
+	}
+}
+
+func TestIsInModule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		module   string
+		main     string
+		file     string
+		expected bool
+	}{
+		{module: "github.com/org/app/pkg", main: "github.com/org/app", file: "pkg/pkg.go", expected: true},
+		{module: "github.com/org/app", main: "github.com/org/app", file: "app.go", expected: true},
+		{module: "main", main: "github.com/org/app", file: "main.go", expected: true},
+		{module: "github.com/org/application", main: "github.com/org/app", file: "app.go", expected: false},
+		{module: "github.com/org/lib", main: "github.com/org/app", file: "github.com/org/lib@v1.2.3/lib.go", expected: false},
+		{module: "net/http", main: "github.com/org/app", file: "net/http/server.go", expected: false},
+		{module: "github.com/org/lib", main: "", file: "/home/user/src/lib/lib.go", expected: true},
+		{module: "github.com/org/lib", main: "", file: "/go/pkg/mod/github.com/org/lib@v1.2.3/lib.go", expected: false},
+		{module: "net/http", main: "command-line-arguments", file: "/usr/local/go/src/net/http/server.go", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module+" in "+tt.main, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, isInModule(tt.module, tt.main, tt.file))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // StackFrame holds metadata for a single call site within a backtrace.
@@ -12,12 +14,15 @@ import (
 //
 // Fields:
 //   - Name (string): simplified function name (without package path) for concise display
+//   - Function (string): fully qualified function name, including the import path
+//     (empty for frames decoded from JSON)
 //   - File (string): full path of the source file where the call originated
 //   - Line (int): exact line number in the source file where the call occurred
 type StackFrame struct {
-	Name string
-	File string
-	Line int
+	Name     string
+	Function string
+	File     string
+	Line     int
 }
 
 // format outputs a single-line representation of the StackFrame using the
@@ -96,31 +101,66 @@ func (f frame) pc() (PC uintptr) {
 // stack.resolveToStackFrames() for consistency.
 //
 // The resolution process:
-//  1. Adjusts the PC for runtime lookup.
-//  2. Retrieves the runtime.Frame using runtime.CallersFrames.
-//  3. Simplifies the function name by removing the package path.
-//  4. Constructs and returns the StackFrame.
+//  1. Retrieves the runtime.Frame using runtime.CallersFrames, or the memoized result.
+//  2. Simplifies the function name by removing the package path.
+//  3. Constructs and returns the StackFrame.
 //
 // Returns:
 //   - stackFrame (StackFrame): enriched metadata for this call site containing
 //     the simplified function name, source file path, and line number.
 func (f frame) resolveToStackFrame() (stackFrame StackFrame) {
-	PC := f.pc()
+	stackFrame = resolvePC(uintptr(f))[0]
 
-	runtimeFrame, _ := runtime.CallersFrames([]uintptr{PC}).Next()
+	return
+}
 
-	name := runtimeFrame.Function
+// resolvedPCs memoizes the resolution of program counters into StackFrame objects.
+// Resolution only depends on the PC, so each PC is walked with runtime.CallersFrames
+// once per process and every later ToString or ToJSON call reuses the result.
+// The cache is bounded by the number of call sites in the program.
+var resolvedPCs sync.Map
 
-	if idx := strings.LastIndex(name, "/"); idx >= 0 {
-		name = name[idx+1:]
+// resolvePC resolves a single PC into its StackFrame objects, memoizing the result.
+// A PC may resolve to several frames when calls were inlined at that site; they are
+// returned innermost first, as runtime.CallersFrames reports them.
+//
+// Parameters:
+//   - PC (uintptr): the program counter to resolve
+//
+// Returns:
+//   - stackFrames ([]StackFrame): the frames for this PC, always at least one
+func resolvePC(PC uintptr) (stackFrames []StackFrame) {
+	if cached, ok := resolvedPCs.Load(PC); ok {
+		stackFrames, _ = cached.([]StackFrame)
+
+		return
 	}
 
-	stackFrame = StackFrame{
-		Name: name,
-		File: runtimeFrame.File,
-		Line: runtimeFrame.Line,
+	runtimeFramesObjects := runtime.CallersFrames([]uintptr{PC})
+
+	for {
+		runtimeFrame, more := runtimeFramesObjects.Next()
+
+		name := runtimeFrame.Function
+
+		if idx := strings.LastIndex(name, "/"); idx >= 0 {
+			name = name[idx+1:]
+		}
+
+		stackFrames = append(stackFrames, StackFrame{
+			Name:     name,
+			Function: runtimeFrame.Function,
+			File:     runtimeFrame.File,
+			Line:     runtimeFrame.Line,
+		})
+
+		if !more {
+			break
+		}
 	}
 
+	resolvedPCs.Store(PC, stackFrames)
+
 	return
 }
 
@@ -130,8 +170,7 @@ func (f frame) resolveToStackFrame() (stackFrame StackFrame) {
 //
 // The stack type provides methods for:
 //   - Resolving PCs to human-readable frames
-//   - Detecting initialization-time errors
-//   - Inserting additional program counters for error wrapping
+//   - Merging additional program counters for error wrapping into a copy
 type stack []uintptr
 
 // resolveToStackFrames resolves the recorded PCs into a slice of detailed StackFrame objects.
@@ -140,7 +179,8 @@ type stack []uintptr
 // presentation of a clear, ordered trace of calls leading up to an error.
 //
 // The resolution process:
-//  1. Converts raw PCs to runtime.Frame objects using runtime.CallersFrames
+//  1. Converts raw PCs to runtime.Frame objects using runtime.CallersFrames,
+//     reusing the memoized result for PCs resolved before (see resolvePC)
 //  2. Extracts and simplifies function names by removing package paths
 //  3. Constructs StackFrame objects with relevant debug information
 //
@@ -150,28 +190,16 @@ type stack []uintptr
 func (s *stack) resolveToStackFrames() (stackFrameObjects []StackFrame) {
 	PCs := *s
 
-	runtimeFramesObjects := runtime.CallersFrames(PCs)
-
-	stackFrameObjects = make([]StackFrame, 0, len(PCs))
+	if len(PCs) == 0 {
+		stackFrameObjects = []StackFrame{{}}
 
-	for {
-		runtimeFrame, more := runtimeFramesObjects.Next()
-
-		name := runtimeFrame.Function
-
-		if idx := strings.LastIndex(name, "/"); idx >= 0 {
-			name = name[idx+1:]
-		}
+		return
+	}
 
-		stackFrameObjects = append(stackFrameObjects, StackFrame{
-			Name: name,
-			File: runtimeFrame.File,
-			Line: runtimeFrame.Line,
-		})
+	stackFrameObjects = make([]StackFrame, 0, len(PCs))
 
-		if !more {
-			break
-		}
+	for _, PC := range PCs {
+		stackFrameObjects = append(stackFrameObjects, resolvePC(PC)...)
 	}
 
 	return
@@ -217,6 +245,31 @@ func (s *stack) insertPC(wrapPCs stack) {
 	}
 }
 
+// merge returns a copy of the stack with the wrap points of the given traces integrated
+// via insertPC. The receiver is left untouched, which keeps errors safe to share.
+//
+// The traces are expected outermost wrap first, as collected while walking an error chain,
+// and are applied innermost first to reproduce the order in which the wraps occurred.
+//
+// Parameters:
+//   - traces ([]*stack): call stacks captured at the wrap points, outermost first
+//
+// Returns:
+//   - merged (*stack): a new stack containing the original PCs and the wrap points
+func (s *stack) merge(traces []*stack) (merged *stack) {
+	v := make(stack, len(*s), len(*s)+len(traces))
+
+	copy(v, *s)
+
+	for i := len(traces) - 1; i >= 0; i-- {
+		v.insertPC(*traces[i])
+	}
+
+	merged = &v
+
+	return
+}
+
 // insert is a helper function that inserts a single uintptr value into a stack slice at a specified index.
 // It creates a new slice with the inserted element while preserving the order of existing elements.
 //
@@ -231,26 +284,19 @@ func insert(s stack, u uintptr, i int) stack {
 	return append(s[:i], append([]uintptr{u}, s[i:]...)...)
 }
 
-// isGlobal checks if the captured call stack includes a global init invocation.
-// This is useful to detect whether an error occurred during package initialization
-// rather than at runtime business logic. It examines each frame's function name
-// looking for runtime initialization markers.
+// isInitFunction reports whether a function name is one of the runtime functions running
+// package initialization (runtime.doInit and runtime.doInit1, formerly runtime.doinit).
+// A stack containing one of them was captured while initializing package-level variables.
 //
-// The check iterates over resolved frames and looks for a specific runtime function name
-// indicating initialization (case-insensitive comparison).
+// Parameters:
+//   - name (string): the fully qualified function name, as returned by runtime.Func.Name
 //
 // Returns:
-//   - isGlobal (bool): true if the stack originates from a global init function, false otherwise
-func (s *stack) isGlobal() (isGlobal bool) {
-	frames := s.resolveToStackFrames()
-
-	for _, f := range frames {
-		if strings.EqualFold(f.Name, "runtime.doinit") {
-			isGlobal = true
+//   - isInit (bool): true if name is a runtime initialization function
+func isInitFunction(name string) (isInit bool) {
+	const prefix = "runtime.doinit"
 
-			return
-		}
-	}
+	isInit = len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
 
 	return
 }
@@ -259,7 +305,8 @@ func (s *stack) isGlobal() (isGlobal bool) {
 // This is useful for annotating errors with the exact call site in application code.
 // The skip parameter allows control over how many stack frames to ascend.
 //
-// It uses runtime.Caller to retrieve the PC and constructs a frame pointer.
+// It uses runtime.Callers to retrieve the raw return PC, the same form as the PCs
+// of a full stack, so both resolve identically even when the call site was inlined.
 // If no valid caller is found, it returns nil.
 //
 // Parameters:
@@ -268,12 +315,13 @@ func (s *stack) isGlobal() (isGlobal bool) {
 // Returns:
 //   - (f *frame): pointer to the resolved frame metadata, or nil if no frames available
 func caller(skip int) (f *frame) {
-	pc, _, _, ok := runtime.Caller(skip)
-	if !ok {
+	var pc [1]uintptr
+
+	if runtime.Callers(skip+1, pc[:]) == 0 {
 		return
 	}
 
-	v := frame(pc)
+	v := frame(pc[0])
 
 	f = &v
 
@@ -284,44 +332,291 @@ func caller(skip int) (f *frame) {
 // It returns a stack object that can be further resolved or formatted. The skip
 // parameter allows the caller to omit wrapper functions from the trace.
 //
+// Only raw PCs are stored; symbolization is deferred until the stack is formatted.
+// The runtime.Func lookup used for filtering also reveals whether the stack runs
+// through package initialization, so global detection costs no extra work.
+//
 // The capture process:
-//  1. Uses runtime.Callers to gather up to 64 raw PCs.
-//  2. Filters out invalid entries and runtime-internal functions (those prefixed with "runtime.").
+//  1. Uses runtime.Callers to gather up to depth raw PCs.
+//  2. Filters out invalid entries and runtime-internal functions (those prefixed with "runtime."),
+//     noting whether one of them is a package initialization function.
 //  3. Returns a pointer to the filtered stack.
 //
 // If no valid frames are found after filtering, an empty stack is returned.
 //
 // Parameters:
 //   - skip (int): number of initial frames to omit (e.g., error wrapper functions)
+//   - depth (int): maximum number of raw PCs to gather
 //
 // Returns:
 //   - s (*stack): stack of filtered program counters ready for resolution,
 //     or empty stack if no frames available
-func callers(skip int) (s *stack) {
-	const depth = 64
+//   - isGlobal (bool): true if the stack originates from package initialization
+func callers(skip, depth int) (s *stack, isGlobal bool) {
+	var buf [defaultStackDepth]uintptr
+
+	PCs := buf[:]
 
-	var PCs [depth]uintptr
+	if depth > defaultStackDepth {
+		PCs = make([]uintptr, depth)
+	}
 
-	c := runtime.Callers(skip, PCs[:])
+	c := runtime.Callers(skip, PCs[:max(depth, 0)])
+
+	s, isGlobal = filterPCs(PCs[:c], depth)
+
+	return
+}
+
+// panicSearchDepth is the number of extra raw PCs gathered by panicCallers to leave room
+// for the deferred functions and runtime frames sitting above the panic site.
+const panicSearchDepth = 32
+
+// panicCallers captures the call stack of a panicking goroutine from within one of its
+// deferred functions. Frames above the innermost runtime.gopanic belong to the deferred
+// call chain (the recovering code), so they are dropped and the trace starts at the
+// function that panicked. Runtime frames between gopanic and the panic site, such as
+// runtime.sigpanic for nil dereferences, are removed by the usual runtime filtering.
+//
+// If no runtime.gopanic frame is found, i.e. the goroutine is not panicking, the
+// stack is captured as by callers.
+//
+// Parameters:
+//   - skip (int): number of initial frames to omit when the goroutine is not panicking
+//   - depth (int): maximum number of PCs to keep
+//
+// Returns:
+//   - s (*stack): stack of filtered program counters ready for resolution
+//   - isGlobal (bool): true if the stack originates from package initialization
+func panicCallers(skip, depth int) (s *stack, isGlobal bool) {
+	PCs := make([]uintptr, max(depth, 0)+panicSearchDepth)
+
+	c := runtime.Callers(1, PCs)
 
 	valid := PCs[:c]
 
-	v := make(stack, 0, c)
+	for i, PC := range valid {
+		if fn := runtime.FuncForPC(PC - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			s, isGlobal = filterPCs(valid[i+1:], depth)
+
+			return
+		}
+	}
+
+	s, isGlobal = filterPCs(valid[min(max(skip-1, 0), c):], depth)
+
+	return
+}
+
+// filterPCs drops invalid entries and runtime-internal functions from raw PCs,
+// keeping at most depth of them, and reports whether one of the dropped functions
+// is a package initialization function.
+//
+// Parameters:
+//   - PCs ([]uintptr): raw program counters as returned by runtime.Callers
+//   - depth (int): maximum number of PCs to keep
+//
+// Returns:
+//   - s (*stack): stack of filtered program counters, or empty stack if none remain
+//   - isGlobal (bool): true if the stack originates from package initialization
+func filterPCs(PCs []uintptr, depth int) (s *stack, isGlobal bool) {
+	v := make(stack, 0, min(len(PCs), max(depth, 0)))
 
-	for _, PC := range valid {
+	for _, PC := range PCs {
 		fn := runtime.FuncForPC(PC - 1)
 		if fn == nil {
 			continue
 		}
 
-		if strings.HasPrefix(fn.Name(), "runtime.") {
+		if name := fn.Name(); strings.HasPrefix(name, "runtime.") {
+			if !isGlobal && isInitFunction(name) {
+				isGlobal = true
+			}
+
 			continue
 		}
 
-		v = append(v, PC)
+		if len(v) < depth {
+			v = append(v, PC)
+		}
 	}
 
 	s = &v
 
 	return
 }
+
+// defaultStackDepth is the maximum number of raw PCs captured per stack unless changed by SetStackDepth.
+const defaultStackDepth = 64
+
+// stackConfig holds the package-level stack capture configuration.
+// It is read on every error creation, so it only uses atomics and a sync.Map.
+//
+// Fields:
+//   - depth (atomic.Int64): maximum number of raw PCs captured per stack
+//   - disabled (atomic.Bool): whether stack capture is disabled for all errors
+//   - disabledTypes (sync.Map): error types (Type) for which stack capture is disabled
+var stackConfig = struct {
+	depth         atomic.Int64
+	disabled      atomic.Bool
+	disabledTypes sync.Map
+}{}
+
+// SetStackDepth sets the maximum number of frames captured per stack trace.
+// A depth of zero or less restores the default of 64.
+// It applies to errors created after the call and is safe for concurrent use.
+//
+// Parameters:
+//   - depth (int): the maximum number of frames
+func SetStackDepth(depth int) {
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+
+	stackConfig.depth.Store(int64(depth))
+}
+
+// SetStackCapture globally enables or disables stack capture.
+// With capture disabled, New, Wrap and Join skip runtime.Callers entirely, which
+// suits hot paths that create many expected errors whose traces nobody reads.
+// It applies to errors created after the call and is safe for concurrent use.
+//
+// Parameters:
+//   - enabled (bool): whether stacks are captured
+func SetStackCapture(enabled bool) {
+	stackConfig.disabled.Store(!enabled)
+}
+
+// SetStackCaptureForType enables or disables stack capture for errors of the given type.
+// The type is the one set through WithType when the error is created.
+// It applies to errors created after the call and is safe for concurrent use.
+//
+// Parameters:
+//   - errType (Type): the error type
+//   - enabled (bool): whether stacks are captured for errType
+func SetStackCaptureForType(errType Type, enabled bool) {
+	if enabled {
+		stackConfig.disabledTypes.Delete(errType)
+
+		return
+	}
+
+	stackConfig.disabledTypes.Store(errType, struct{}{})
+}
+
+// captureOptions holds the per-error stack capture configuration.
+// It is set by options such as WithCallerSkip before the stack is captured.
+//
+// Fields:
+//   - skip (int): extra frames to skip, for helpers creating errors on behalf of their callers
+//   - depth (int): maximum number of frames to capture, or zero for the package default
+//   - disabled (bool): whether stack capture is disabled for this error
+type captureOptions struct {
+	skip     int
+	depth    int
+	disabled bool
+}
+
+// enabled reports whether a stack should be captured for an error of the given type,
+// considering the per-error, global and per-type switches.
+//
+// Parameters:
+//   - errType (Type): the type of the error being created
+//
+// Returns:
+//   - enabled (bool): true if the stack should be captured
+func (o captureOptions) enabled(errType Type) (enabled bool) {
+	if o.disabled || stackConfig.disabled.Load() {
+		return
+	}
+
+	if errType != "" {
+		if _, ok := stackConfig.disabledTypes.Load(errType); ok {
+			return
+		}
+	}
+
+	enabled = true
+
+	return
+}
+
+// callers captures the call stack as configured, or returns nil if capture is disabled.
+// The skip parameter has the same meaning as for the package-level callers function;
+// this method accounts for its own frame.
+//
+// Parameters:
+//   - skip (int): number of initial frames to omit
+//   - errType (Type): the type of the error being created
+//
+// Returns:
+//   - s (*stack): the captured stack, or nil if capture is disabled
+//   - isGlobal (bool): true if the stack originates from package initialization
+func (o captureOptions) callers(skip int, errType Type) (s *stack, isGlobal bool) {
+	if !o.enabled(errType) {
+		return
+	}
+
+	s, isGlobal = callers(skip+1+o.skip, o.stackDepth())
+
+	return
+}
+
+// panicCallers captures the stack of a panicking goroutine as configured, or returns nil
+// if capture is disabled. The skip parameter is only used when the goroutine is not
+// panicking and has the same meaning as for callers.
+//
+// Parameters:
+//   - skip (int): number of initial frames to omit when the goroutine is not panicking
+//   - errType (Type): the type of the error being created
+//
+// Returns:
+//   - s (*stack): the captured stack, or nil if capture is disabled
+//   - isGlobal (bool): true if the stack originates from package initialization
+func (o captureOptions) panicCallers(skip int, errType Type) (s *stack, isGlobal bool) {
+	if !o.enabled(errType) {
+		return
+	}
+
+	s, isGlobal = panicCallers(skip+1+o.skip, o.stackDepth())
+
+	return
+}
+
+// stackDepth returns the maximum number of frames to capture, falling back from the
+// per-error depth to the package-level depth and finally to the default.
+//
+// Returns:
+//   - depth (int): the maximum number of frames
+func (o captureOptions) stackDepth() (depth int) {
+	depth = o.depth
+	if depth <= 0 {
+		depth = int(stackConfig.depth.Load())
+	}
+
+	if depth <= 0 {
+		depth = defaultStackDepth
+	}
+
+	return
+}
+
+// caller captures the immediate caller's frame as configured, or returns nil if capture is disabled.
+// The skip parameter has the same meaning as for the package-level caller function;
+// this method accounts for its own frame.
+//
+// Parameters:
+//   - skip (int): number of frames to ascend
+//   - errType (Type): the type of the error being created
+//
+// Returns:
+//   - f (*frame): the captured frame, or nil if capture is disabled
+func (o captureOptions) caller(skip int, errType Type) (f *frame) {
+	if !o.enabled(errType) {
+		return
+	}
+
+	f = caller(skip + 1 + o.skip)
+
+	return
+}
@@ -167,7 +167,7 @@ func TestFrame_resolveToStackFrame(t *testing.T) {
 
 	f := frame(pc[0])
 
-	frames := runtime.CallersFrames([]uintptr{pc[0] - 1})
+	frames := runtime.CallersFrames([]uintptr{pc[0]})
 
 	runtimeFrame, _ := frames.Next()
 
@@ -184,6 +184,21 @@ func TestFrame_resolveToStackFrame(t *testing.T) {
 	assert.Equal(t, runtimeFrame.Line, result.Line)
 }
 
+func TestResolvePC(t *testing.T) {
+	t.Parallel()
+
+	pc := [1]uintptr{}
+
+	runtime.Callers(1, pc[:])
+
+	first := resolvePC(pc[0])
+	second := resolvePC(pc[0])
+
+	require.NotEmpty(t, first)
+	assert.Contains(t, first[0].Name, "TestResolvePC")
+	assert.Same(t, &first[0], &second[0], "Resolution should be memoized")
+}
+
 func TestStack_resolveToStackFrames(t *testing.T) {
 	t.Parallel()
 
@@ -278,37 +293,111 @@ func TestStack_insertPC(t *testing.T) {
 	}
 }
 
-func TestStack_isGlobal(t *testing.T) {
+func TestStack_merge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		original stack
+		traces   []*stack
+		expected stack
+	}{
+		{
+			name:     "no traces",
+			original: stack{0x111, 0x222},
+			traces:   nil,
+			expected: stack{0x111, 0x222},
+		},
+		{
+			name:     "single trace",
+			original: stack{0x111, 0x222, 0x444},
+			traces:   []*stack{{0x333, 0x444}},
+			expected: stack{0x111, 0x222, 0x333, 0x444},
+		},
+		{
+			name:     "innermost trace applied first",
+			original: stack{0x111},
+			traces:   []*stack{{0x333}, {0x222}},
+			expected: stack{0x111, 0x222, 0x333},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := make(stack, len(tt.original))
+
+			copy(original, tt.original)
+
+			result := original.merge(tt.traces)
+
+			assert.Equal(t, tt.expected, *result)
+			assert.Equal(t, tt.original, original, "Original stack should not be modified")
+		})
+	}
+}
+
+func TestIsInitFunction(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name     string
-		pcs      stack
+		function string
 		expected bool
 	}{
 		{
-			name:     "empty stack",
-			pcs:      stack{},
+			name:     "empty name",
+			function: "",
 			expected: false,
 		},
 		{
-			name:     "non-init stack",
-			pcs:      stack{0x123, 0x456},
+			name:     "non-init function",
+			function: "main.main",
 			expected: false,
 		},
+		{
+			name:     "legacy init function",
+			function: "runtime.doinit",
+			expected: true,
+		},
+		{
+			name:     "init functions",
+			function: "runtime.doInit1",
+			expected: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			result := tt.pcs.isGlobal()
+			result := isInitFunction(tt.function)
 
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+var globalErr = New("global")
+
+func TestCallers_isGlobal(t *testing.T) {
+	t.Parallel()
+
+	_, isGlobal := callers(0, defaultStackDepth)
+
+	assert.False(t, isGlobal)
+	assert.True(t, globalErr.(*root).isGlobal)
+
+	wrappedErr := Wrap(globalErr, "wrapper")
+
+	rootErr := Cause(wrappedErr).(*root)
+
+	assert.NotSame(t, globalErr, rootErr, "Wrapping a global error should not reuse its initialization trace")
+	assert.True(t, Is(wrappedErr, globalErr))
+	assert.Equal(t, Unpack(wrappedErr).ErrChain[0].Stack[0], Unpack(wrappedErr).ErrRoot.Stack[0])
+}
+
 func TestCaller(t *testing.T) {
 	t.Parallel()
 
@@ -334,7 +423,7 @@ func TestCaller(t *testing.T) {
 func TestCallers(t *testing.T) {
 	t.Parallel()
 
-	result := callers(0)
+	result, _ := callers(0, defaultStackDepth)
 
 	require.NotNil(t, result)
 
@@ -346,7 +435,7 @@ func TestCallers(t *testing.T) {
 		assert.False(t, strings.HasPrefix(frame.Name, "runtime."), "Should filter out runtime frames")
 	}
 
-	innerResult := callers(2)
+	innerResult, _ := callers(2, defaultStackDepth)
 
 	require.NotNil(t, innerResult)
 
@@ -356,7 +445,7 @@ func TestCallers(t *testing.T) {
 		assert.NotEqual(t, frames[0].Name, innerFrames[0].Name, "First frame should be different when skipping")
 	}
 
-	emptyResult := callers(1000)
+	emptyResult, _ := callers(1000, defaultStackDepth)
 
 	assert.Empty(t, *emptyResult)
 }
@@ -436,3 +525,168 @@ func TestStack_resolveToStackFrames_InvalidPCs(t *testing.T) {
 	assert.Empty(t, result[0].File)
 	assert.Zero(t, result[0].Line)
 }
+
+func TestCallers_depth(t *testing.T) {
+	t.Parallel()
+
+	result, _ := callers(0, 1)
+
+	require.NotNil(t, result)
+	assert.LessOrEqual(t, len(*result), 1)
+
+	deep, _ := callers(0, defaultStackDepth*2)
+
+	require.NotNil(t, deep)
+	assert.NotEmpty(t, *deep)
+}
+
+func newHelperError() error {
+	return New("helper", WithCallerSkip(1))
+}
+
+func TestCaptureOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("caller skip", func(t *testing.T) {
+		t.Parallel()
+
+		err := newHelperError()
+
+		frames := Unpack(err).ErrRoot.Stack
+
+		require.NotEmpty(t, frames)
+		assert.Contains(t, frames[0].Name, "TestCaptureOptions")
+	})
+
+	t.Run("caller skip on wrap", func(t *testing.T) {
+		t.Parallel()
+
+		wrapHelper := func(err error) error {
+			return Wrap(err, "helper", WithCallerSkip(1))
+		}
+
+		err := wrapHelper(New("base"))
+
+		frames := Unpack(err).ErrChain[0].Stack
+
+		require.Len(t, frames, 1)
+		assert.Contains(t, frames[0].Name, "TestCaptureOptions")
+	})
+
+	t.Run("stack depth", func(t *testing.T) {
+		t.Parallel()
+
+		err := New("shallow", WithStackDepth(1))
+
+		assert.Len(t, err.(*root).StackFrames(), 1)
+	})
+
+	t.Run("without stack", func(t *testing.T) {
+		t.Parallel()
+
+		err := Wrap(New("base", WithoutStack()), "wrapper", WithoutStack())
+
+		assert.Nil(t, Cause(err).(*root).trace)
+		assert.Nil(t, err.(*wrapped).trace)
+		assert.Empty(t, err.(*wrapped).StackFrames())
+		assert.Equal(t, "wrapper\n\nbase", ToString(err, FormatWithTrace()))
+	})
+
+	t.Run("disabled for type", func(t *testing.T) {
+		t.Parallel()
+
+		const errType Type = "TEST_CAPTURE_DISABLED"
+
+		SetStackCaptureForType(errType, false)
+
+		disabled := New("disabled", WithType(errType))
+
+		SetStackCaptureForType(errType, true)
+
+		enabled := New("enabled", WithType(errType))
+
+		assert.Nil(t, disabled.(*root).trace)
+		assert.NotEmpty(t, enabled.(*root).StackFrames())
+	})
+}
+
+//nolint:paralleltest // modifies the package-level stack configuration
+func TestStackConfig(t *testing.T) {
+	t.Run("disabled globally", func(t *testing.T) {
+		SetStackCapture(false)
+
+		defer SetStackCapture(true)
+
+		err := Join(New("error1"), Wrap(New("error2"), "wrapper"))
+
+		assert.Empty(t, err.(*joined).StackFrames())
+		assert.NotContains(t, ToString(err, FormatWithTrace()), "Trace:")
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		SetStackDepth(1)
+
+		defer SetStackDepth(0)
+
+		err := New("error")
+
+		assert.Len(t, err.(*root).StackFrames(), 1)
+	})
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = New("error")
+	}
+}
+
+func BenchmarkNewWithoutStack(b *testing.B) {
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = New("error", WithoutStack())
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	err := New("error")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = Wrap(err, "wrapper")
+	}
+}
+
+func BenchmarkJoin(b *testing.B) {
+	err1 := New("error1")
+	err2 := New("error2")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = Join(err1, err2)
+	}
+}
+
+func BenchmarkToString(b *testing.B) {
+	err := Wrap(Wrap(New("error"), "wrapper1"), "wrapper2")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = ToString(err, FormatWithTrace())
+	}
+}
+
+func BenchmarkToJSON(b *testing.B) {
+	err := Wrap(Wrap(New("error"), "wrapper1"), "wrapper2")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = ToJSON(err, FormatWithTrace())
+	}
+}
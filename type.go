@@ -0,0 +1,145 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// typeParents holds the registered type hierarchy, mapping each registered Type to its parent.
+// It is written at registration, typically during package initialization, and read on every
+// IsA, so it uses a sync.Map. Writes are serialized by typeParentsMu, so a registration's
+// cycle check and store happen as one step.
+var (
+	typeParents   sync.Map
+	typeParentsMu sync.Mutex
+)
+
+// RegisterType registers errType as a child of parent, so errors of type errType (and of its
+// own descendants) match parent in IsType, Type.IsA and Is with TypeMatcher. Registering a
+// type again replaces its parent. It returns errType so types can be declared and registered
+// at once:
+//
+//	var (
+//		TypeDB        = errors.Type("db")
+//		TypeDBTimeout = errors.RegisterType("db.timeout", TypeDB)
+//	)
+//
+// It panics if parent is errType or one of its descendants, as the hierarchy would have a cycle.
+//
+// Parameters:
+//   - errType (Type): the type to register
+//   - parent (Type): the parent type, or empty to make errType a top-level type
+//
+// Returns:
+//   - registered (Type): errType
+func RegisterType(errType, parent Type) (registered Type) {
+	typeParentsMu.Lock()
+	defer typeParentsMu.Unlock()
+
+	if parent == "" {
+		typeParents.Delete(errType)
+
+		registered = errType
+
+		return
+	}
+
+	if parent.IsA(errType) {
+		panic(fmt.Sprintf("errors: registering type %q under %q creates a cycle", errType, parent))
+	}
+
+	typeParents.Store(errType, parent)
+
+	registered = errType
+
+	return
+}
+
+// Parent returns the parent type registered with RegisterType.
+//
+// Returns:
+//   - parent (Type): the parent type, or empty if t is a top-level or unregistered type
+func (t Type) Parent() (parent Type) {
+	if v, ok := typeParents.Load(t); ok {
+		parent, _ = v.(Type)
+	}
+
+	return
+}
+
+// IsA reports whether t is ancestor or one of its descendants in the registered hierarchy.
+// The empty type is not a descendant of anything, and nothing descends from it.
+//
+// Parameters:
+//   - ancestor (Type): the type to match
+//
+// Returns:
+//   - matches (bool): true if t is ancestor or descends from it
+func (t Type) IsA(ancestor Type) (matches bool) {
+	if t == "" || ancestor == "" {
+		return
+	}
+
+	for ; t != ""; t = t.Parent() {
+		if t == ancestor {
+			matches = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// typeMatcher is the Is target returned by TypeMatcher.
+type typeMatcher Type
+
+// TypeMatcher returns an Is target matching the errors of type t or of its descendants,
+// regardless of their messages:
+//
+//	if errors.Is(err, errors.TypeMatcher(TypeDB)) {
+//		...
+//	}
+//
+// Parameters:
+//   - t (Type): the type to match
+//
+// Returns:
+//   - matcher (error): the Is target
+func TypeMatcher(t Type) (matcher error) {
+	matcher = typeMatcher(t)
+
+	return
+}
+
+// Error implements the error interface, returning the matched type name.
+//
+// Returns:
+//   - msg (string): the type name
+func (m typeMatcher) Error() (msg string) {
+	msg = string(m)
+
+	return
+}
+
+// IsType reports whether err or any error it wraps has type t or one of its descendants.
+// It walks the whole tree, joined errors and external wrappers included, and checks
+// every error with a Type() Type method.
+//
+// Parameters:
+//   - err (error): the error to inspect
+//   - t (Type): the type to match
+//
+// Returns:
+//   - matches (bool): true if an error of type t, or of one of its descendants, is found
+func IsType(err error, t Type) (matches bool) {
+	for err := range All(err) {
+		if x, ok := err.(interface{ Type() Type }); ok && x.Type().IsA(t) {
+			matches = true
+
+			return
+		}
+	}
+
+	return
+}
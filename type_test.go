@@ -0,0 +1,141 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testTypeDB        = Type("test.db")
+	testTypeDBTimeout = RegisterType("test.db.timeout", testTypeDB)
+	testTypeDBDeep    = RegisterType("test.db.timeout.deep", testTypeDBTimeout)
+	testTypeHTTP      = Type("test.http")
+)
+
+func TestType_IsA(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		errType  Type
+		ancestor Type
+		expected bool
+	}{
+		{name: "same type", errType: testTypeDB, ancestor: testTypeDB, expected: true},
+		{name: "child", errType: testTypeDBTimeout, ancestor: testTypeDB, expected: true},
+		{name: "grandchild", errType: testTypeDBDeep, ancestor: testTypeDB, expected: true},
+		{name: "parent", errType: testTypeDB, ancestor: testTypeDBTimeout, expected: false},
+		{name: "unrelated", errType: testTypeDBTimeout, ancestor: testTypeHTTP, expected: false},
+		{name: "empty type", errType: "", ancestor: testTypeDB, expected: false},
+		{name: "empty ancestor", errType: testTypeDB, ancestor: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.errType.IsA(tt.ancestor))
+		})
+	}
+
+	assert.Equal(t, testTypeDBTimeout, testTypeDBDeep.Parent())
+	assert.Empty(t, testTypeDB.Parent())
+}
+
+func TestRegisterType(t *testing.T) {
+	t.Parallel()
+
+	child := RegisterType("test.register.child", "test.register.parent")
+
+	assert.Equal(t, Type("test.register.parent"), child.Parent())
+
+	assert.Panics(t, func() {
+		RegisterType("test.register.parent", child)
+	})
+
+	RegisterType(child, "")
+
+	assert.Empty(t, child.Parent())
+}
+
+func TestRegisterType_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	for i := range 100 {
+		a := Type(fmt.Sprintf("test.concurrent.a%d", i))
+		b := Type(fmt.Sprintf("test.concurrent.b%d", i))
+
+		var (
+			wg     sync.WaitGroup
+			panics atomic.Int32
+		)
+
+		register := func(errType, parent Type) {
+			defer func() {
+				if recover() != nil {
+					panics.Add(1)
+				}
+			}()
+
+			RegisterType(errType, parent)
+		}
+
+		wg.Go(func() { register(a, b) })
+		wg.Go(func() { register(b, a) })
+
+		wg.Wait()
+
+		assert.Equal(t, int32(1), panics.Load())
+		assert.NotEqual(t, a.IsA(b), b.IsA(a))
+	}
+}
+
+func TestIsType(t *testing.T) {
+	t.Parallel()
+
+	timeoutErr := New("query timed out", WithType(testTypeDBTimeout))
+
+	tests := []struct {
+		name     string
+		err      error
+		errType  Type
+		expected bool
+	}{
+		{name: "nil", err: nil, errType: testTypeDB, expected: false},
+		{name: "exact type", err: timeoutErr, errType: testTypeDBTimeout, expected: true},
+		{name: "ancestor type", err: timeoutErr, errType: testTypeDB, expected: true},
+		{name: "descendant type", err: timeoutErr, errType: testTypeDBDeep, expected: false},
+		{name: "unrelated type", err: timeoutErr, errType: testTypeHTTP, expected: false},
+		{name: "wrapped", err: Wrap(timeoutErr, "loading user"), errType: testTypeDB, expected: true},
+		{name: "typed wrapper", err: Wrap(errors.New("external"), "calling api", WithType(testTypeHTTP)), errType: testTypeHTTP, expected: true},
+		{name: "external wrapper", err: fmt.Errorf("context: %w", timeoutErr), errType: testTypeDB, expected: true},
+		{name: "joined", err: Join(errors.New("external"), timeoutErr), errType: testTypeDB, expected: true},
+		{name: "untyped", err: New("untyped"), errType: testTypeDB, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, IsType(tt.err, tt.errType))
+		})
+	}
+
+	t.Run("type targets", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("context: %w", Wrap(timeoutErr, "loading user"))
+
+		assert.True(t, Is(err, TypeMatcher(testTypeDB)))
+		assert.True(t, errors.Is(err, TypeMatcher(testTypeDBTimeout)))
+		assert.False(t, Is(err, TypeMatcher(testTypeHTTP)))
+		assert.False(t, Is(New("query timed out"), TypeMatcher(testTypeDB)))
+
+		assert.EqualError(t, TypeMatcher(testTypeDB), "test.db")
+	})
+}
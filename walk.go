@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"iter"
+)
+
+// WalkFunc is called by Walk for each error of a tree.
+//
+// Parameters:
+//   - node (error): the error being visited
+//   - depth (int): the number of unwrap steps from the walked error (0 for the walked error itself)
+//   - path ([]int): the branch taken at each step, 0 for Unwrap() error and the index of the
+//     error for Unwrap() []error; it is reused between calls and must be copied to be retained
+//
+// Returns:
+//   - more (bool): false to stop the walk
+type WalkFunc func(node error, depth int, path []int) (more bool)
+
+// Walk visits err and every error it wraps, depth-first and outermost first.
+// Single-error chains are followed through Unwrap() error, and each error of a
+// multi-error (Unwrap() []error, including joined errors) is walked in order before
+// moving on. Nil errors are skipped.
+//
+// Walk is the traversal behind Is, As, Unpack, All and Chain, so they all see errors in the same order.
+//
+// Parameters:
+//   - err (error): the error to walk
+//   - fn (WalkFunc): called for each error; returning false stops the walk
+func Walk(err error, fn WalkFunc) {
+	walk(err, 0, make([]int, 0, 8), fn)
+}
+
+// walk is the recursive helper for Walk.
+//
+// Parameters:
+//   - err (error): the error to walk
+//   - depth (int): the depth of err
+//   - path ([]int): the path to err
+//   - fn (WalkFunc): the visit function
+//
+// Returns:
+//   - more (bool): false if fn stopped the walk
+func walk(err error, depth int, path []int, fn WalkFunc) (more bool) {
+	for err != nil {
+		if !fn(err, depth, path) {
+			return
+		}
+
+		depth++
+
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+			path = append(path, 0)
+		case interface{ Unwrap() []error }:
+			for i, err := range x.Unwrap() {
+				if !walk(err, depth, append(path, i), fn) {
+					return
+				}
+			}
+
+			more = true
+
+			return
+		default:
+			more = true
+
+			return
+		}
+	}
+
+	more = true
+
+	return
+}
+
+// All returns an iterator over err and every error it wraps, in Walk order.
+//
+// Usage:
+//
+//	for e := range errors.All(err) {
+//		...
+//	}
+//
+// Parameters:
+//   - err (error): the error to iterate
+//
+// Returns:
+//   - seq (iter.Seq[error]): the iterator
+func All(err error) (seq iter.Seq[error]) {
+	return func(yield func(error) bool) {
+		Walk(err, func(node error, _ int, _ []int) (more bool) {
+			more = yield(node)
+
+			return
+		})
+	}
+}
+
+// Chain returns an iterator over the single-error chain of err: err itself, then each error
+// returned by Unwrap() error, down to the error Cause returns. It does not descend into
+// multi-errors, which end the chain. Each error is yielded with its depth.
+//
+// Parameters:
+//   - err (error): the error to iterate
+//
+// Returns:
+//   - seq (iter.Seq2[int, error]): the iterator of depths and errors
+func Chain(err error) (seq iter.Seq2[int, error]) {
+	return func(yield func(int, error) bool) {
+		Walk(err, func(node error, depth int, _ []int) (more bool) {
+			more = yield(depth, node)
+
+			if _, ok := node.(interface{ Unwrap() []error }); ok {
+				more = false
+			}
+
+			return
+		})
+	}
+}
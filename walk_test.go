@@ -0,0 +1,136 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	err1 := New("1")
+	err2 := New("2")
+	err2a := Wrap(err2, "2a")
+	external := errors.New("external")
+	inner := Join(err2a, external)
+	joinedErr := Join(err1, inner)
+	outer := fmt.Errorf("outer: %w", joinedErr)
+
+	type visit struct {
+		node  error
+		depth int
+		path  []int
+	}
+
+	var visits []visit
+
+	Walk(outer, func(node error, depth int, path []int) (more bool) {
+		visits = append(visits, visit{node: node, depth: depth, path: slices.Clone(path)})
+
+		more = true
+
+		return
+	})
+
+	expected := []visit{
+		{node: outer, depth: 0, path: []int{}},
+		{node: joinedErr, depth: 1, path: []int{0}},
+		{node: err1, depth: 2, path: []int{0, 0}},
+		{node: inner, depth: 2, path: []int{0, 1}},
+		{node: err2a, depth: 3, path: []int{0, 1, 0}},
+		{node: err2, depth: 4, path: []int{0, 1, 0, 0}},
+		{node: external, depth: 3, path: []int{0, 1, 1}},
+	}
+
+	require.Len(t, visits, len(expected))
+
+	for i := range expected {
+		assert.Same(t, expected[i].node, visits[i].node)
+		assert.Equal(t, expected[i].depth, visits[i].depth)
+		assert.Equal(t, expected[i].path, visits[i].path)
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		count := 0
+
+		Walk(outer, func(node error, _ int, _ []int) (more bool) {
+			count++
+
+			more = node != err1
+
+			return
+		})
+
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		Walk(nil, func(error, int, []int) bool {
+			t.Fatal("visited a nil error")
+
+			return true
+		})
+	})
+}
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+
+	err1 := New("1")
+	err2 := Wrap(New("2"), "2a")
+	joinedErr := Join(err1, err2)
+
+	var nodes []error
+
+	for err := range All(joinedErr) {
+		nodes = append(nodes, err)
+	}
+
+	assert.Equal(t, []error{joinedErr, err1, err2, Cause(err2)}, nodes)
+
+	for err := range All(joinedErr) {
+		if err == err1 {
+			break
+		}
+	}
+
+	assert.Empty(t, slices.Collect(All(nil)))
+}
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	rootErr := New("root")
+	wrappedErr := Wrap(rootErr, "wrapper")
+	joinedErr := Join(wrappedErr, New("other"))
+	outer := fmt.Errorf("outer: %w", joinedErr)
+
+	var depths []int
+
+	var nodes []error
+
+	for depth, err := range Chain(wrappedErr) {
+		depths = append(depths, depth)
+		nodes = append(nodes, err)
+	}
+
+	assert.Equal(t, []int{0, 1}, depths)
+	assert.Equal(t, []error{wrappedErr, rootErr}, nodes)
+
+	nodes = nodes[:0]
+
+	for _, err := range Chain(outer) {
+		nodes = append(nodes, err)
+	}
+
+	assert.Equal(t, []error{outer, joinedErr}, nodes, "Chain should stop at multi-errors")
+}